@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/server"
+)
+
+const usage = `Usage: glance-china [command] [flags]
+
+Commands:
+  serve      启动仪表板服务（默认）
+
+Flags:
+`
+
+func main() {
+	args := os.Args[1:]
+
+	// 未指定子命令时默认执行 serve，兼容 "glance-china -config ..." 的调用方式
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		if err := serve(args); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", command)
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	configPath := flags.String("config", envOrDefault("GLANCE_CONFIG", "glance.yml"), "配置文件路径")
+	host := flags.String("host", "", "监听地址，覆盖配置文件中的 server.host")
+	port := flags.Int("port", 0, "监听端口，覆盖配置文件中的 server.port")
+	reloadInterval := flags.Duration("reload-interval", 2*time.Second, "配置文件变更检查间隔，0 表示关闭热加载")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	watcher, err := config.NewWatcher(*configPath, *reloadInterval)
+	if err != nil {
+		return err
+	}
+	cfg := watcher.Config()
+
+	if *host != "" {
+		cfg.Server.Host = *host
+	}
+	if *port != 0 {
+		cfg.Server.Port = *port
+	} else if envPort := os.Getenv("GLANCE_PORT"); envPort != "" {
+		p, err := strconv.Atoi(envPort)
+		if err != nil {
+			return fmt.Errorf("invalid GLANCE_PORT: %s", envPort)
+		}
+		cfg.Server.Port = p
+	}
+
+	if err := config.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *reloadInterval > 0 {
+		watcher.OnReload(srv.Reload)
+		go watcher.Start(ctx)
+	}
+
+	return srv.Run(ctx)
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
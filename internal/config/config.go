@@ -2,10 +2,9 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"time"
 	
-	"gopkg.in/yaml.v3"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // AppConfig 应用配置
@@ -40,19 +39,48 @@ type ServerConfig struct {
 	APISources map[string]APISourceConfig `yaml:"api-sources"`
 	Cache      CacheConfig               `yaml:"cache"`
 	RateLimit  RateLimitConfig           `yaml:"rate-limit"`
+	Performance service.PerformanceConfig `yaml:"performance"`
+	Refresh    RefreshConfig             `yaml:"refresh"`
+	Proxy      ProxyConfig               `yaml:"proxy"`
 }
 
+// RefreshConfig 后台预热配置，在组件缓存过期前主动刷新数据
+type RefreshConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Lead        time.Duration `yaml:"lead"`         // 提前于缓存过期的时间，默认为缓存时长的 10%
+	Jitter      time.Duration `yaml:"jitter"`       // 刷新时间的随机提前量上限，默认为缓存时长的 10%
+	IdleTimeout time.Duration `yaml:"idle-timeout"` // 页面无人访问超过该时长后暂停刷新，默认 30 分钟
+}
+
+// 服务层配置类型，配置文件直接解析为服务层使用的结构
+type (
+	APISourceConfig = service.APISourceConfig
+	CacheConfig     = service.CacheConfig
+	RateLimitConfig = service.RateLimitConfig
+	ProxyConfig     = service.ProxyConfig
+)
 
 // LoadConfig 加载配置文件
 func LoadConfig(configPath string) (*AppConfig, error) {
-	data, err := os.ReadFile(configPath)
+	config, _, err := loadConfig(configPath)
+	return config, err
+}
+
+// loadConfig 加载配置文件，同时返回加载过程中读取的全部文件（含 !include 和 !file 引用）
+func loadConfig(configPath string) (*AppConfig, []string, error) {
+	e := &expander{}
+	root, err := e.parseFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, nil, err
 	}
 	
 	var config AppConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := root.Decode(&config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	
+	if config.Server.Port == 0 {
+		config.Server.Port = 8080
 	}
 	
 	if config.Locale.Default == "" {
@@ -68,7 +96,7 @@ func LoadConfig(configPath string) (*AppConfig, error) {
 		config.Locale.NumberFormat.UseChineseUnits = true
 	}
 	
-	return &config, nil
+	return &config, e.files, nil
 }
 
 // ValidateConfig 验证配置
@@ -93,5 +121,42 @@ func ValidateConfig(config *AppConfig) error {
 		}
 	}
 	
+	// 验证出站代理和 API 源的连接配置
+	if err := config.Server.Proxy.Validate(); err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	for name, source := range config.Server.APISources {
+		if err := source.Validate(); err != nil {
+			return fmt.Errorf("api source %s: %w", name, err)
+		}
+	}
+	
+	// 验证页面
+	slugs := make(map[string]bool)
+	for i, page := range config.Pages {
+		if page.Name == "" {
+			return fmt.Errorf("page #%d has no name", i+1)
+		}
+		slug := page.GetSlug()
+		if slugs[slug] {
+			return fmt.Errorf("duplicate page slug: %s", slug)
+		}
+		slugs[slug] = true
+		
+		for _, column := range page.Columns {
+			if column.Size != "" && column.Size != "small" && column.Size != "full" {
+				return fmt.Errorf("page %s: invalid column size: %s", page.Name, column.Size)
+			}
+			for _, w := range column.Widgets {
+				if w.Widget == nil {
+					continue
+				}
+				if err := w.Widget.Validate(nil); err != nil {
+					return fmt.Errorf("page %s: %s widget at line %d: %w", page.Name, w.Type, w.Line, err)
+				}
+			}
+		}
+	}
+	
 	return nil
 }
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fileTag 从文件读取配置值的自定义标签，例如 "token: !file /run/secrets/gitee_token"
+	fileTag = "!file"
+	// includeTag 引入另一个 YAML 文件作为节点内容，例如 "pages: !include pages.yml"
+	includeTag = "!include"
+	// maxIncludeDepth 最大引入层级，防止循环引用
+	maxIncludeDepth = 10
+)
+
+// expander 解析配置文件并展开环境变量引用、!file 和 !include 标签
+type expander struct {
+	files []string // 已读取的文件
+	depth int
+}
+
+// parseFile 读取并解析 YAML 文件，返回展开后的文档节点
+func (e *expander) parseFile(path string) (*yaml.Node, error) {
+	if e.depth > maxIncludeDepth {
+		return nil, fmt.Errorf("%s: include depth exceeds %d", path, maxIncludeDepth)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	e.files = append(e.files, path)
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	e.depth++
+	defer func() { e.depth-- }()
+
+	if err := e.expandNode(&root, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &root, nil
+}
+
+// expandNode 展开节点树中标量值的环境变量引用，并解析自定义标签
+func (e *expander) expandNode(node *yaml.Node, baseDir string) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := e.expandNode(child, baseDir); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		// 仅展开值，不展开键
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := e.expandNode(node.Content[i], baseDir); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return e.expandScalar(node, baseDir)
+	}
+
+	// 别名节点指向的锚点已在定义处展开
+	return nil
+}
+
+func (e *expander) expandScalar(node *yaml.Node, baseDir string) error {
+	value, err := expandEnv(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+
+	switch node.Tag {
+	case fileTag:
+		path := resolvePath(baseDir, value)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("line %d: failed to read %s: %w", node.Line, value, err)
+		}
+		e.files = append(e.files, path)
+		node.Value = strings.TrimRight(string(content), "\r\n")
+		node.Tag = "!!str"
+		return nil
+	case includeTag:
+		doc, err := e.parseFile(resolvePath(baseDir, value))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if len(doc.Content) == 0 {
+			return fmt.Errorf("line %d: included file %s is empty", node.Line, value)
+		}
+		*node = *doc.Content[0]
+		return nil
+	}
+
+	if value == node.Value {
+		return nil
+	}
+
+	node.Value = value
+	// 未加引号的值按展开后的内容重新推断类型，使 "port: ${PORT}" 可解析为整数
+	if node.Style == 0 {
+		node.Tag = ""
+	}
+	return nil
+}
+
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// expandEnv 展开 shell 风格的环境变量引用：
+//
+//	${VAR}           变量值，未设置时为空
+//	${VAR:-default}  未设置或为空时使用 default（${VAR-default} 仅在未设置时使用）
+//	${VAR:?message}  未设置或为空时报错（${VAR?message} 仅在未设置时报错）
+//	$${VAR}          输出字面量 ${VAR}
+func expandEnv(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			b.WriteString(value)
+			break
+		}
+
+		// $${ 转义为字面量
+		if start > 0 && value[start-1] == '$' {
+			b.WriteString(value[:start])
+			value = value[start+1:]
+			end := strings.IndexByte(value, '}')
+			if end < 0 {
+				b.WriteString(value)
+				break
+			}
+			b.WriteString(value[:end+1])
+			value = value[end+1:]
+			continue
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", value)
+		}
+		end += start
+
+		b.WriteString(value[:start])
+		expanded, err := expandReference(value[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(expanded)
+		value = value[end+1:]
+	}
+
+	return b.String(), nil
+}
+
+func expandReference(ref string) (string, error) {
+	name, op, arg := ref, "", ""
+	if i := strings.IndexAny(ref, ":-?"); i >= 0 {
+		name, op = ref[:i], ref[i:]
+		if strings.HasPrefix(op, ":-") || strings.HasPrefix(op, ":?") {
+			op, arg = op[:2], op[2:]
+		} else if op[0] == '-' || op[0] == '?' {
+			op, arg = op[:1], op[1:]
+		} else {
+			return "", fmt.Errorf("invalid variable reference ${%s}", ref)
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("invalid variable reference ${%s}", ref)
+	}
+
+	value, set := os.LookupEnv(name)
+	switch op {
+	case ":-":
+		if value == "" {
+			return arg, nil
+		}
+	case "-":
+		if !set {
+			return arg, nil
+		}
+	case ":?":
+		if value == "" {
+			return "", missingVariableError(name, arg)
+		}
+	case "?":
+		if !set {
+			return "", missingVariableError(name, arg)
+		}
+	}
+
+	return value, nil
+}
+
+func missingVariableError(name, message string) error {
+	if message == "" {
+		message = "required environment variable is not set"
+	}
+	return fmt.Errorf("%s: %s", name, message)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// ThemeConfig 主题配置
+type ThemeConfig struct {
+	BackgroundColor          *HSLColor `yaml:"background-color"`
+	PrimaryColor             *HSLColor `yaml:"primary-color"`
+	PositiveColor            *HSLColor `yaml:"positive-color"`
+	NegativeColor            *HSLColor `yaml:"negative-color"`
+	Light                    bool      `yaml:"light"`
+	ContrastMultiplier       float64   `yaml:"contrast-multiplier"`
+	TextSaturationMultiplier float64   `yaml:"text-saturation-multiplier"`
+	CustomCSSFile            string    `yaml:"custom-css-file"`
+	LazyLoading              bool      `yaml:"lazy-loading"`
+}
+
+// HSLColor 以 "色相 饱和度 亮度" 形式配置的颜色，例如 "240 8 9"
+type HSLColor struct {
+	Hue        float64
+	Saturation float64
+	Lightness  float64
+}
+
+func (c *HSLColor) UnmarshalYAML(node *yaml.Node) error {
+	parts := strings.Fields(node.Value)
+	if len(parts) != 3 {
+		return fmt.Errorf("line %d: invalid color %q, expected \"hue saturation lightness\"", node.Line, node.Value)
+	}
+
+	values := make([]float64, 3)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid color %q: %w", node.Line, node.Value, err)
+		}
+		values[i] = value
+	}
+
+	if values[0] < 0 || values[0] > 360 || values[1] < 0 || values[1] > 100 || values[2] < 0 || values[2] > 100 {
+		return fmt.Errorf("line %d: color %q out of range", node.Line, node.Value)
+	}
+
+	c.Hue, c.Saturation, c.Lightness = values[0], values[1], values[2]
+	return nil
+}
+
+// String 返回 CSS hsl() 表示
+func (c *HSLColor) String() string {
+	return fmt.Sprintf("hsl(%g %g%% %g%%)", c.Hue, c.Saturation, c.Lightness)
+}
+
+// PageConfig 页面配置
+type PageConfig struct {
+	Name                  string         `yaml:"name"`
+	Slug                  string         `yaml:"slug"`
+	Locale                string         `yaml:"locale"`
+	Width                 string         `yaml:"width"` // default, slim, wide
+	HideDesktopNavigation bool           `yaml:"hide-desktop-navigation"`
+	Columns               []ColumnConfig `yaml:"columns"`
+}
+
+// ColumnConfig 页面列配置
+type ColumnConfig struct {
+	Size    string         `yaml:"size"` // small, full
+	Widgets []WidgetConfig `yaml:"widgets"`
+}
+
+// WidgetConfig 组件配置，按 type 解析为 widget 包中注册的具体组件
+type WidgetConfig struct {
+	Type   string
+	Line   int
+	Widget widget.Widget // 未注册的组件类型为 nil
+}
+
+// GetSlug 获取页面路径，未配置时由页面名称生成
+func (p *PageConfig) GetSlug() string {
+	if p.Slug != "" {
+		return p.Slug
+	}
+	return strings.Join(strings.Fields(strings.ToLower(p.Name)), "-")
+}
+
+// cacheDurationSetter 支持通过 cache 配置缓存时长的组件
+type cacheDurationSetter interface {
+	SetCacheDuration(duration time.Duration)
+}
+
+func (w *WidgetConfig) UnmarshalYAML(node *yaml.Node) error {
+	var header struct {
+		Type  string `yaml:"type"`
+		Cache string `yaml:"cache"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return err
+	}
+	if header.Type == "" {
+		return fmt.Errorf("line %d: widget has no type", node.Line)
+	}
+
+	var cacheDuration time.Duration
+	if header.Cache != "" {
+		duration, err := time.ParseDuration(header.Cache)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("line %d: invalid cache duration %q", node.Line, header.Cache)
+		}
+		cacheDuration = duration
+	}
+
+	w.Type = header.Type
+	w.Line = node.Line
+
+	// 未注册的组件类型保留原始信息，由页面渲染时提示
+	if !widget.IsRegistered(header.Type) {
+		return nil
+	}
+
+	instance, err := widget.CreateWidget(header.Type)
+	if err != nil {
+		return err
+	}
+	if err := node.Decode(instance); err != nil {
+		return fmt.Errorf("line %d: invalid %s widget: %w", node.Line, header.Type, err)
+	}
+	if setter, ok := instance.(cacheDurationSetter); ok && cacheDuration > 0 {
+		setter.SetCacheDuration(cacheDuration)
+	}
+
+	w.Widget = instance
+	return nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watcher 配置文件监听器，文件（含 !include、!file 引用的文件）变化时重新加载配置
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	current  *AppConfig
+	files    map[string]fileState
+	pending  map[string]fileState // 检测到变化时的文件状态，连续两次检查不变后才重新加载
+	contents map[string][]byte // 当前生效配置对应的文件内容，用于输出差异
+	handlers []func(*AppConfig)
+	mu       sync.RWMutex
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher 加载配置文件并创建监听器
+func NewWatcher(configPath string, interval time.Duration) (*Watcher, error) {
+	config, files, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	w := &Watcher{
+		path:     configPath,
+		interval: interval,
+		current:  config,
+	}
+	w.snapshot(files)
+	w.contents = readFiles(files)
+
+	return w, nil
+}
+
+// Config 获取当前生效的配置
+func (w *Watcher) Config() *AppConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnReload 注册配置重新加载后的回调
+func (w *Watcher) OnReload(handler func(*AppConfig)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Start 开始监听，直到ctx取消
+func (w *Watcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 检查文件变化并尝试重新加载
+func (w *Watcher) check() {
+	if !w.settled() {
+		return
+	}
+
+	config, files, err := loadConfig(w.path)
+	if err == nil {
+		err = ValidateConfig(config)
+	}
+
+	if err != nil {
+		// 保留当前配置，记录新配置的差异便于排查
+		w.mu.Lock()
+		files := w.knownFiles()
+		w.snapshot(files)
+		w.mu.Unlock()
+
+		log.Printf("config reload failed, keeping current config: %v", err)
+		w.logDiff(readFiles(files))
+		return
+	}
+
+	contents := readFiles(files)
+	if w.sameContents(contents) {
+		w.mu.Lock()
+		w.snapshot(files)
+		w.mu.Unlock()
+		return
+	}
+
+	w.mu.Lock()
+	w.current = config
+	w.contents = contents
+	w.snapshot(files)
+	handlers := append([]func(*AppConfig){}, w.handlers...)
+	w.mu.Unlock()
+
+	log.Printf("config reloaded from %s", w.path)
+	for _, handler := range handlers {
+		handler(config)
+	}
+}
+
+// settled 已知文件有变化，且自上次检查以来不再变化时返回 true，
+// 连续保存或编辑器分步写入时只在写入结束后重新加载一次，避免读到写了一半的文件
+func (w *Watcher) settled() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	states := statFiles(w.knownFiles())
+	if sameStates(states, w.files) {
+		w.pending = nil
+		return false
+	}
+	if w.pending == nil || !sameStates(states, w.pending) {
+		w.pending = states
+		return false
+	}
+
+	w.pending = nil
+	return true
+}
+
+// snapshot 记录文件状态，调用方需持有锁（初始化时除外）
+func (w *Watcher) snapshot(files []string) {
+	w.files = statFiles(files)
+}
+
+// statFiles 读取文件的修改时间和大小，不存在的文件记为零值
+func statFiles(files []string) map[string]fileState {
+	states := make(map[string]fileState, len(files))
+	for _, path := range files {
+		state := fileState{}
+		if info, err := os.Stat(path); err == nil {
+			state = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		states[path] = state
+	}
+	return states
+}
+
+func sameStates(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		other, exists := b[path]
+		if !exists || !state.modTime.Equal(other.modTime) || state.size != other.size {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Watcher) knownFiles() []string {
+	files := make([]string, 0, len(w.files))
+	for path := range w.files {
+		files = append(files, path)
+	}
+	return files
+}
+
+func (w *Watcher) sameContents(contents map[string][]byte) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(contents) != len(w.contents) {
+		return false
+	}
+	for path, content := range contents {
+		if !bytes.Equal(content, w.contents[path]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Watcher) logDiff(contents map[string][]byte) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for path, content := range contents {
+		if old, exists := w.contents[path]; exists && !bytes.Equal(old, content) {
+			log.Printf("rejected changes in %s:\n%s", path, diffLines(string(old), string(content)))
+		}
+	}
+}
+
+func readFiles(files []string) map[string][]byte {
+	contents := make(map[string][]byte, len(files))
+	for _, path := range files {
+		if data, err := os.ReadFile(path); err == nil {
+			contents[path] = data
+		}
+	}
+	return contents
+}
+
+// maxDiffCells 行差异计算的最大规模，超过时只输出摘要
+const maxDiffCells = 4 << 20
+
+// diffLines 基于最长公共子序列输出逐行差异
+func diffLines(oldText, newText string) string {
+	a := strings.Split(oldText, "\n")
+	b := strings.Split(newText, "\n")
+
+	if len(a)*len(b) > maxDiffCells {
+		return fmt.Sprintf("  (%d lines -> %d lines)", len(a), len(b))
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&out, "  %d: - %s\n", i+1, a[i])
+			i++
+		default:
+			fmt.Fprintf(&out, "  %d: + %s\n", j+1, b[j])
+			j++
+		}
+	}
+
+	return out.String()
+}
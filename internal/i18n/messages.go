@@ -35,12 +35,17 @@ var chineseMessages = map[string]string{
 	"time.yesterday":    "昨天",
 	"time.tomorrow":     "明天",
 	
+	// 缓存
+	"cache.stale_since": "数据已过期（%s），正在刷新",
+	
 	// 数字单位
 	"number.thousand":   "千",
 	"number.ten_thousand": "万",
 	"number.hundred_million": "亿",
 	"number.views":      "播放",
 	"number.likes":      "点赞",
+	"number.coins":      "投币",
+	"number.favorites":  "收藏",
 	"number.comments":   "评论",
 	"number.shares":     "分享",
 	"number.followers":  "粉丝",
@@ -102,6 +107,8 @@ var chineseMessages = map[string]string{
 	"error.network":         "网络连接错误",
 	"error.timeout":         "请求超时",
 	"error.rate_limit":      "请求频率过高",
+	"error.upstream_throttled": "上游服务限流中，稍后自动重试",
+	"error.partial_up_masters": "以下UP主的视频获取失败：%s",
 	"error.not_found":       "未找到内容",
 	"error.server_error":    "服务器错误",
 	"error.invalid_config":  "配置错误",
@@ -176,12 +183,21 @@ var englishMessages = map[string]string{
 	"time.yesterday":    "yesterday",
 	"time.tomorrow":     "tomorrow",
 	
+	// 缓存
+	"cache.stale_since": "Stale since %s, refreshing",
+	
+	// 错误消息
+	"error.upstream_throttled": "Upstream is throttling requests, retrying later",
+	"error.partial_up_masters": "Failed to load videos from: %s",
+	
 	// 数字单位
 	"number.thousand":   "K",
 	"number.ten_thousand": "K",
 	"number.hundred_million": "M",
 	"number.views":      "views",
 	"number.likes":      "likes",
+	"number.coins":      "coins",
+	"number.favorites":  "favorites",
 	"number.comments":   "comments",
 	"number.shares":     "shares",
 	"number.followers":  "followers",
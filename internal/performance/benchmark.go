@@ -101,10 +101,11 @@ func (b *Benchmarker) RunBenchmark(ctx context.Context, testFunc TestFunc) (*Ben
 				if requestDuration > result.MaxTime {
 					result.MaxTime = requestDuration
 				}
+				// 检查是否达到请求数限制
+				done := b.config.RequestCount > 0 && result.TotalRequests >= b.config.RequestCount
 				mu.Unlock()
 				
-				// 检查是否达到请求数限制
-				if b.config.RequestCount > 0 && result.TotalRequests >= b.config.RequestCount {
+				if done {
 					break
 				}
 			}
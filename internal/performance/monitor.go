@@ -27,11 +27,24 @@ type Metrics struct {
 	APIRequests       map[string]int64 `json:"api_requests"`
 	APIErrors         map[string]int64 `json:"api_errors"`
 	APIResponseTimes  map[string]time.Duration `json:"api_response_times"`
+	APIRetries        map[string]int64 `json:"api_retries"`
+	APIThrottles      map[string]int64 `json:"api_throttles"` // 上游返回限流或风控响应的次数
 	
 	// 缓存指标
 	CacheHits         int64 `json:"cache_hits"`
 	CacheMisses       int64 `json:"cache_misses"`
 	CacheSize         int64 `json:"cache_size"`
+	CacheEvictions    int64 `json:"cache_evictions"`
+	CacheTiers        []CacheTierMetrics `json:"cache_tiers,omitempty"`
+	
+	// 请求合并指标
+	CoalescedLoads    int64 `json:"coalesced_loads"`    // 与并发加载合并的组件数据加载次数
+	CoalescedRequests int64 `json:"coalesced_requests"` // 与并发请求合并的上游 API 请求次数
+	
+	// 熔断指标
+	CircuitBreakers   map[string]string `json:"circuit_breakers,omitempty"` // 各 API 源熔断器状态：closed、open、half-open
+	ThrottledUntil    map[string]time.Time `json:"throttled_until,omitempty"` // 处于上游限流退避期的 API 源及退避结束时间
+	RateFactors       map[string]float64   `json:"rate_factors,omitempty"`    // 被自动降速的 API 源当前配额相对配置值的比例
 	
 	// 系统指标
 	MemoryUsage       uint64 `json:"memory_usage"`
@@ -48,6 +61,13 @@ type Metrics struct {
 	mu sync.RWMutex
 }
 
+// CacheTierMetrics 多级缓存中单个层级的命中统计
+type CacheTierMetrics struct {
+	Name   string `json:"name"`
+	Hits   int64  `json:"hits"`
+	Misses int64  `json:"misses"`
+}
+
 // Collector 指标收集器接口
 type Collector interface {
 	Collect(ctx context.Context) error
@@ -61,6 +81,8 @@ func NewMonitor() *Monitor {
 			APIRequests:      make(map[string]int64),
 			APIErrors:        make(map[string]int64),
 			APIResponseTimes: make(map[string]time.Duration),
+			APIRetries:       make(map[string]int64),
+			APIThrottles:     make(map[string]int64),
 			WidgetLoadTimes:  make(map[string]time.Duration),
 			WidgetErrors:     make(map[string]int64),
 		},
@@ -136,6 +158,7 @@ func (m *Monitor) GetMetrics() *Metrics {
 		CacheHits:        m.metrics.CacheHits,
 		CacheMisses:      m.metrics.CacheMisses,
 		CacheSize:        m.metrics.CacheSize,
+		CacheEvictions:   m.metrics.CacheEvictions,
 		MemoryUsage:      m.metrics.MemoryUsage,
 		GoroutineCount:   m.metrics.GoroutineCount,
 		GCPauseTime:      m.metrics.GCPauseTime,
@@ -143,6 +166,8 @@ func (m *Monitor) GetMetrics() *Metrics {
 		APIRequests:      make(map[string]int64),
 		APIErrors:        make(map[string]int64),
 		APIResponseTimes: make(map[string]time.Duration),
+		APIRetries:       make(map[string]int64),
+		APIThrottles:     make(map[string]int64),
 		WidgetLoadTimes:  make(map[string]time.Duration),
 		WidgetErrors:     make(map[string]int64),
 	}
@@ -157,6 +182,12 @@ func (m *Monitor) GetMetrics() *Metrics {
 	for k, v := range m.metrics.APIResponseTimes {
 		metrics.APIResponseTimes[k] = v
 	}
+	for k, v := range m.metrics.APIRetries {
+		metrics.APIRetries[k] = v
+	}
+	for k, v := range m.metrics.APIThrottles {
+		metrics.APIThrottles[k] = v
+	}
 	for k, v := range m.metrics.WidgetLoadTimes {
 		metrics.WidgetLoadTimes[k] = v
 	}
@@ -209,6 +240,22 @@ func (m *Monitor) RecordAPIRequest(service string, duration time.Duration, isErr
 	}
 }
 
+// RecordAPIRetry 记录一次API请求重试
+func (m *Monitor) RecordAPIRetry(service string) {
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+	
+	m.metrics.APIRetries[service]++
+}
+
+// RecordAPIThrottle 记录一次上游限流或风控响应
+func (m *Monitor) RecordAPIThrottle(service string) {
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+	
+	m.metrics.APIThrottles[service]++
+}
+
 // RecordWidgetLoad 记录组件加载
 func (m *Monitor) RecordWidgetLoad(widgetType string, duration time.Duration, isError bool) {
 	m.metrics.mu.Lock()
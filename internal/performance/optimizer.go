@@ -2,7 +2,9 @@ package performance
 
 import (
 	"context"
+	"fmt"
 	"runtime"
+	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -72,7 +74,7 @@ func NewOptimizer(config OptimizerConfig) *Optimizer {
 func (o *Optimizer) applySystemOptimizations() {
 	// 设置GC百分比
 	if o.config.GCPercent > 0 {
-		runtime.SetGCPercent(o.config.GCPercent)
+		debug.SetGCPercent(o.config.GCPercent)
 	}
 	
 	// 设置最大CPU使用数
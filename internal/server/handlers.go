@@ -0,0 +1,276 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/i18n"
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// widgetLoadTimeout 单个页面加载组件数据的最长时间
+const widgetLoadTimeout = 15 * time.Second
+
+var templateFuncs = template.FuncMap{
+	// number 以整数形式输出 JSON 解码得到的数字
+	"number": func(v interface{}) string {
+		if f, ok := v.(float64); ok && f == math.Trunc(f) {
+			return strconv.FormatInt(int64(f), 10)
+		}
+		return fmt.Sprint(v)
+	},
+}
+
+// pageView 页面渲染数据
+type pageView struct {
+	Title   string
+	Slug    string
+	Locale  string
+	Page    *Page
+	Pages   []*Page
+	Theme   themeView
+	Columns []columnView
+}
+
+// themeView 主题颜色，未配置时使用默认配色
+type themeView struct {
+	Background string
+	Primary    string
+	Positive   string
+	Negative   string
+	Light      bool
+}
+
+type columnView struct {
+	Size    string
+	Widgets []template.HTML
+}
+
+// widgetView 组件渲染数据
+type widgetView struct {
+	Type  string
+	Title string
+	Data  map[string]interface{}
+	Error     string
+	Throttled bool   // 上游限流，Error 为退避提示而非故障
+	Stale     string // 数据已过期、正在后台刷新时的提示
+}
+
+func (s *Server) setupRoutes() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+
+	engine := gin.New()
+	engine.Use(gin.Logger(), gin.Recovery())
+
+	engine.GET("/health", s.handleHealth)
+	engine.GET("/metrics", s.handleMetrics)
+	engine.GET("/", s.handleIndex)
+	engine.GET("/:page", s.handlePage)
+
+	return engine
+}
+
+// healthChecker 支持健康检查的缓存后端
+type healthChecker interface {
+	IsHealthy(ctx context.Context) bool
+}
+
+func (s *Server) handleHealth(c *gin.Context) {
+	status := gin.H{"status": "ok"}
+
+	// 缓存后端不可用时仍可回源，只报告状态不影响存活检查
+	if checker, ok := s.manager.GetCache().(healthChecker); ok {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		status["cache"] = "ok"
+		if !checker.IsHealthy(ctx) {
+			status["cache"] = "unavailable"
+		}
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, s.manager.GetMetrics())
+}
+
+func (s *Server) handleIndex(c *gin.Context) {
+	st := s.state.Load()
+	if len(st.pages) == 0 {
+		c.String(http.StatusNotFound, "no pages configured")
+		return
+	}
+	s.renderPage(c, st, st.pages[0])
+}
+
+func (s *Server) handlePage(c *gin.Context) {
+	st := s.state.Load()
+	page, exists := st.slugs[c.Param("page")]
+	if !exists {
+		c.String(http.StatusNotFound, "page not found")
+		return
+	}
+	s.renderPage(c, st, page)
+}
+
+func (s *Server) renderPage(c *gin.Context, st *state, page *Page) {
+	locale := st.config.Locale.Default
+	if page.Locale != "" {
+		locale = page.Locale
+	}
+
+	s.scheduler.markViewed(page.Slug)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), widgetLoadTimeout)
+	defer cancel()
+	ctx = s.widgetContext(ctx, locale)
+
+	view := pageView{
+		Title:   page.Name,
+		Slug:    page.Slug,
+		Locale:  locale,
+		Page:    page,
+		Pages:   st.pages,
+		Theme:   newThemeView(st.config.Theme),
+		Columns: make([]columnView, len(page.Columns)),
+	}
+
+	// 并发加载所有组件
+	var wg sync.WaitGroup
+	for i, column := range page.Columns {
+		view.Columns[i] = columnView{
+			Size:    column.Size,
+			Widgets: make([]template.HTML, len(column.Widgets)),
+		}
+		for j, instance := range column.Widgets {
+			wg.Add(1)
+			go func(i, j int, instance *WidgetInstance) {
+				defer wg.Done()
+				view.Columns[i].Widgets[j] = s.renderWidget(ctx, instance, locale)
+			}(i, j, instance)
+		}
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&buf, "page", view); err != nil {
+		log.Printf("failed to render page %s: %v", page.Slug, err)
+		c.String(http.StatusInternalServerError, "failed to render page")
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func newThemeView(theme config.ThemeConfig) themeView {
+	view := themeView{
+		Background: "hsl(240 8% 9%)",
+		Primary:    "hsl(43 50% 70%)",
+		Positive:   "hsl(120 60% 50%)",
+		Negative:   "hsl(0 70% 70%)",
+		Light:      theme.Light,
+	}
+
+	if theme.BackgroundColor != nil {
+		view.Background = theme.BackgroundColor.String()
+	}
+	if theme.PrimaryColor != nil {
+		view.Primary = theme.PrimaryColor.String()
+	}
+	if theme.PositiveColor != nil {
+		view.Positive = theme.PositiveColor.String()
+	}
+	if theme.NegativeColor != nil {
+		view.Negative = theme.NegativeColor.String()
+	}
+
+	return view
+}
+
+func (s *Server) renderWidget(ctx context.Context, instance *WidgetInstance, locale string) template.HTML {
+	view := widgetView{Type: instance.Type}
+	localizer := i18n.NewLocalizer(locale)
+
+	if instance.Err != nil {
+		view.Error = instance.Err.Error()
+	} else {
+		data, status, err := s.loadWidgetData(ctx, instance, locale)
+		if errors.Is(err, service.ErrUpstreamThrottled) {
+			view.Error = localizer.T("error.upstream_throttled")
+			view.Throttled = true
+		} else if err != nil {
+			view.Error = fmt.Sprintf("%s: %v", localizer.T("error"), err)
+		} else {
+			view.Data = data
+			if title, ok := data["title"].(string); ok {
+				view.Title = title
+			}
+			if status.Stale {
+				view.Stale = localizer.T("cache.stale_since", localizer.FormatRelativeTime(status.StaleSince))
+			}
+		}
+	}
+
+	name := "widget-" + instance.Type
+	if view.Error != "" || s.tmpl.Lookup(name) == nil {
+		name = "widget-generic"
+	}
+
+	var buf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&buf, name, view); err != nil {
+		log.Printf("failed to render widget %s: %v", instance.Type, err)
+		return ""
+	}
+
+	return template.HTML(buf.String())
+}
+
+// widgetContext 携带组件加载数据所需的服务管理器和语言
+func (s *Server) widgetContext(ctx context.Context, locale string) context.Context {
+	ctx = context.WithValue(ctx, "serviceManager", s.manager)
+	return context.WithValue(ctx, "locale", locale)
+}
+
+// widgetCacheKey 组件数据在缓存中的 key
+func widgetCacheKey(w widget.Widget, locale string) string {
+	return "widget:" + w.GetCacheKey(widget.ConfigMap{"locale": locale})
+}
+
+// widgetLoader 从上游获取组件数据并记录加载耗时
+func (s *Server) widgetLoader(w widget.Widget) service.LoadFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		start := time.Now()
+		raw, err := w.GetData(ctx, nil)
+		s.manager.GetMonitor().RecordWidgetLoad(w.GetType(), time.Since(start), err != nil)
+		return raw, err
+	}
+}
+
+// loadWidgetData 获取组件数据，优先读取缓存；缓存过期但仍在宽限期内时返回旧数据并在后台刷新
+func (s *Server) loadWidgetData(ctx context.Context, instance *WidgetInstance, locale string) (map[string]interface{}, service.CacheStatus, error) {
+	w := instance.Widget
+
+	// 数据经 JSON 编码后缓存，使缓存数据与实时数据渲染方式一致
+	var data map[string]interface{}
+	status, err := s.manager.GetStaleCache().Fetch(ctx, widgetCacheKey(w, locale), w.GetCacheDuration(), &data, s.widgetLoader(w))
+	if err != nil {
+		return nil, status, err
+	}
+
+	return data, status, nil
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+const (
+	// refreshCheckInterval 调度器最长休眠时间，用于及时发现配置变化和重新被访问的页面
+	refreshCheckInterval = time.Second
+
+	// defaultRefreshIdleTimeout 页面无人访问后继续刷新的默认时长
+	defaultRefreshIdleTimeout = 30 * time.Minute
+)
+
+// scheduler 在组件缓存过期前后台刷新数据，使访问者不必等待上游接口
+type scheduler struct {
+	server  *Server
+	started time.Time
+	entries map[string]*refreshEntry // 按缓存 key 索引，多个页面中相同的组件只刷新一次
+
+	views map[string]time.Time // 各页面最近一次被访问的时间
+	mu    sync.Mutex
+}
+
+// refreshEntry 一个需要预热的组件
+type refreshEntry struct {
+	widget widget.Widget
+	locale string
+	next   time.Time
+	active bool // 所在页面中至少有一个最近被访问过
+}
+
+func newScheduler(s *Server) *scheduler {
+	return &scheduler{
+		server:  s,
+		started: time.Now(),
+		entries: make(map[string]*refreshEntry),
+		views:   make(map[string]time.Time),
+	}
+}
+
+// markViewed 记录页面被访问，暂停刷新的页面随之恢复
+func (sc *scheduler) markViewed(slug string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.views[slug] = time.Now()
+}
+
+// lastViewed 页面最近一次被访问的时间，从未访问过的页面视为启动时访问过
+func (sc *scheduler) lastViewed(slug string) time.Time {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if viewed, exists := sc.views[slug]; exists {
+		return viewed
+	}
+	return sc.started
+}
+
+// Run 执行后台刷新，直到ctx取消；未启用时只定期检查配置是否开启
+func (sc *scheduler) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(sc.tick(ctx, time.Now()))
+		}
+	}
+}
+
+// tick 发起所有到期组件的刷新，返回距离下一次检查的时间；
+// 一个组件会发出多少个上游请求无法预知，请求的节奏由各 API 源的限流器控制
+func (sc *scheduler) tick(ctx context.Context, now time.Time) time.Duration {
+	st := sc.server.state.Load()
+	refresh := st.config.Server.Refresh
+	if !refresh.Enabled {
+		return refreshCheckInterval
+	}
+
+	sc.sync(st, refresh, now)
+
+	wait := refreshCheckInterval
+	for key, entry := range sc.entries {
+		if !entry.active {
+			continue
+		}
+
+		if now.Before(entry.next) {
+			wait = minDuration(wait, entry.next.Sub(now))
+			continue
+		}
+
+		entry.next = now.Add(refreshInterval(entry.widget.GetCacheDuration(), refresh))
+		wait = minDuration(wait, entry.next.Sub(now))
+		go sc.refresh(ctx, key, entry.widget, entry.locale)
+	}
+
+	return wait
+}
+
+// sync 按当前页面树更新待刷新的组件，新出现的组件在随机抖动后立即预热
+func (sc *scheduler) sync(st *state, refresh config.RefreshConfig, now time.Time) {
+	idle := refresh.IdleTimeout
+	if idle <= 0 {
+		idle = defaultRefreshIdleTimeout
+	}
+
+	for _, entry := range sc.entries {
+		entry.active = false
+	}
+
+	seen := make(map[string]bool)
+	for _, page := range st.pages {
+		locale := st.config.Locale.Default
+		if page.Locale != "" {
+			locale = page.Locale
+		}
+		active := now.Sub(sc.lastViewed(page.Slug)) < idle
+
+		for _, column := range page.Columns {
+			for _, instance := range column.Widgets {
+				if instance.Widget == nil {
+					continue
+				}
+
+				key := widgetCacheKey(instance.Widget, locale)
+				seen[key] = true
+
+				entry, exists := sc.entries[key]
+				if !exists {
+					entry = &refreshEntry{
+						locale: locale,
+						next:   now.Add(randomDuration(refresh.Jitter)),
+					}
+					sc.entries[key] = entry
+				}
+				// 配置热加载后使用新的组件实例
+				entry.widget = instance.Widget
+				entry.active = entry.active || active
+			}
+		}
+	}
+
+	for key := range sc.entries {
+		if !seen[key] {
+			delete(sc.entries, key)
+		}
+	}
+}
+
+// refresh 获取组件最新数据写入缓存
+func (sc *scheduler) refresh(ctx context.Context, key string, w widget.Widget, locale string) {
+	// 预热请求超出限流配额时排队等待，不与页面请求争抢令牌
+	ctx, cancel := context.WithTimeout(service.WithRateLimitWait(ctx), widgetLoadTimeout)
+	defer cancel()
+
+	s := sc.server
+	if err := s.manager.GetStaleCache().Refresh(s.widgetContext(ctx, locale), key, w.GetCacheDuration(), s.widgetLoader(w)); err != nil {
+		log.Printf("failed to refresh widget %s: %v", w.GetType(), err)
+	}
+}
+
+// refreshInterval 两次刷新之间的间隔：缓存时长减去提前量和随机抖动，至少为缓存时长的一半
+func refreshInterval(ttl time.Duration, refresh config.RefreshConfig) time.Duration {
+	lead, jitter := refresh.Lead, refresh.Jitter
+	if lead <= 0 {
+		lead = ttl / 10
+	}
+	if jitter <= 0 {
+		jitter = ttl / 10
+	}
+
+	interval := ttl - lead - randomDuration(jitter)
+	if interval < ttl/2 {
+		interval = ttl / 2
+	}
+	return interval
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
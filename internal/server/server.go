@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Server 仪表板HTTP服务
+type Server struct {
+	addr      string
+	manager   *service.ServiceManager
+	tmpl      *template.Template
+	engine    *gin.Engine
+	scheduler *scheduler
+	state     atomic.Pointer[state]
+}
+
+// state 由配置生成、可整体热替换的页面树
+type state struct {
+	config *config.AppConfig
+	pages  []*Page
+	slugs  map[string]*Page
+}
+
+// Page 已实例化的页面
+type Page struct {
+	Name                  string
+	Slug                  string
+	Locale                string
+	Width                 string
+	HideDesktopNavigation bool
+	Columns               []*Column
+}
+
+// Column 已实例化的列
+type Column struct {
+	Size    string
+	Widgets []*WidgetInstance
+}
+
+// WidgetInstance 页面中的组件实例
+type WidgetInstance struct {
+	Type   string
+	Widget widget.Widget
+	Err    error // 组件不可用时的错误，渲染为错误提示
+}
+
+// New 根据应用配置创建服务
+func New(cfg *config.AppConfig) (*Server, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	s := &Server{
+		addr:    net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port)),
+		manager: service.NewServiceManager(serviceConfig(cfg)),
+		tmpl:    tmpl,
+	}
+	s.state.Store(newState(cfg))
+	s.scheduler = newScheduler(s)
+	s.engine = s.setupRoutes()
+
+	return s, nil
+}
+
+// Reload 使用新配置原子替换页面树和服务客户端，监听地址变更需重启生效
+func (s *Server) Reload(cfg *config.AppConfig) {
+	s.manager.UpdateConfig(serviceConfig(cfg))
+	s.state.Store(newState(cfg))
+}
+
+func newState(cfg *config.AppConfig) *state {
+	st := &state{
+		config: cfg,
+		slugs:  make(map[string]*Page),
+	}
+
+	for i := range cfg.Pages {
+		page := buildPage(&cfg.Pages[i])
+		st.pages = append(st.pages, page)
+		st.slugs[page.Slug] = page
+	}
+
+	return st
+}
+
+// serviceConfig 由应用配置生成服务层配置
+func serviceConfig(cfg *config.AppConfig) *service.Config {
+	return &service.Config{
+		Region:      cfg.Server.Region,
+		APISources:  cfg.Server.APISources,
+		Cache:       cfg.Server.Cache,
+		RateLimit:   cfg.Server.RateLimit,
+		Performance: cfg.Server.Performance,
+		Proxy:       cfg.Server.Proxy,
+	}
+}
+
+func buildPage(pageConfig *config.PageConfig) *Page {
+	page := &Page{
+		Name:                  pageConfig.Name,
+		Slug:                  pageConfig.GetSlug(),
+		Locale:                pageConfig.Locale,
+		Width:                 pageConfig.Width,
+		HideDesktopNavigation: pageConfig.HideDesktopNavigation,
+	}
+
+	for _, columnConfig := range pageConfig.Columns {
+		column := &Column{Size: columnConfig.Size}
+		for _, widgetConfig := range columnConfig.Widgets {
+			instance := &WidgetInstance{
+				Type:   widgetConfig.Type,
+				Widget: widgetConfig.Widget,
+			}
+			if instance.Widget == nil {
+				log.Printf("unsupported widget type %s at line %d", widgetConfig.Type, widgetConfig.Line)
+				instance.Err = fmt.Errorf("unsupported widget type: %s", widgetConfig.Type)
+			}
+			column.Widgets = append(column.Widgets, instance)
+		}
+		page.Columns = append(page.Columns, column)
+	}
+
+	return page
+}
+
+// Handler 返回HTTP处理器
+func (s *Server) Handler() http.Handler {
+	return s.engine
+}
+
+// Run 启动HTTP服务和后台预热调度器，直到ctx取消后优雅关闭
+func (s *Server) Run(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.engine,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go s.scheduler.Run(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("glance-china listening on %s", s.addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return httpServer.Shutdown(shutdownCtx)
+}
@@ -11,6 +11,9 @@ import (
 	"time"
 )
 
+// defaultRequestTimeout API 源未配置 timeout 时单个请求的超时时间
+const defaultRequestTimeout = 10 * time.Second
+
 // BaseClient 基础客户端实现
 type BaseClient struct {
 	name      string
@@ -18,8 +21,15 @@ type BaseClient struct {
 	timeout   time.Duration
 	headers   map[string]string
 	client    *http.Client
+	retry     RetryConfig
+	sender    Requester
+	onRetry   func(ctx context.Context) error
+	throttle  func(resp *APIResponse) bool
 }
 
+// Requester 发送 API 请求；ServiceManager 通过它让类型化客户端的请求经过限流、工作池、监控和备用源
+type Requester func(ctx context.Context, req *APIRequest) (*APIResponse, error)
+
 func (b *BaseClient) GetName() string {
 	return b.name
 }
@@ -43,6 +53,37 @@ func (b *BaseClient) SetRateLimit(requests int, duration time.Duration) {
 	// 基础实现，子类可以重写
 }
 
+func (b *BaseClient) setSender(sender Requester) {
+	b.sender = sender
+}
+
+func (b *BaseClient) setTransport(transport http.RoundTripper) {
+	b.client.Transport = transport
+}
+
+func (b *BaseClient) setRetryHook(throttled func(resp *APIResponse) bool, onRetry func(ctx context.Context) error) {
+	b.throttle = throttled
+	b.onRetry = onRetry
+}
+
+// send 发送类型化方法构造的请求，未绑定 ServiceManager 时直接请求上游；4xx/5xx 响应以 *APIError 返回
+func (b *BaseClient) send(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+	if b.sender != nil {
+		return b.sender(ctx, req)
+	}
+	
+	resp, err := b.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(b.name, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Request 发送请求；按 API 源的重试策略重试网络错误和可重试的状态码，返回最后一次尝试的结果。
+// 限流响应不重试，交给 ServiceManager 退避
 func (b *BaseClient) Request(ctx context.Context, req *APIRequest) (*APIResponse, error) {
 	// 构建完整URL
 	fullURL, err := b.buildURL(req.Path, req.Params)
@@ -50,16 +91,61 @@ func (b *BaseClient) Request(ctx context.Context, req *APIRequest) (*APIResponse
 		return nil, err
 	}
 	
-	// 构建请求体
-	var body io.Reader
+	// 构建请求体，每次尝试重新读取
+	var bodyBytes []byte
 	if req.Body != nil {
-		bodyBytes, err := json.Marshal(req.Body)
+		bodyBytes, err = json.Marshal(req.Body)
 		if err != nil {
 			return nil, err
 		}
+	}
+	
+	throttled := b.throttle
+	if throttled == nil {
+		throttled = b.throttled
+	}
+	
+	attempts := b.retry.attempts(req.Method)
+	for attempt := 1; ; attempt++ {
+		resp, err := b.do(ctx, req, fullURL, bodyBytes)
+		if attempt >= attempts || !b.retry.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		if resp != nil && throttled(resp) {
+			return resp, err
+		}
+		// 上游要求等待的时长超过 max-delay 时不再重试，返回这次的结果
+		delay, ok := b.retry.delay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+		// 每次重试同样占用限流配额，取不到令牌时放弃重试
+		if b.onRetry != nil && b.onRetry(ctx) != nil {
+			return resp, err
+		}
+	}
+}
+
+// do 发送一次 HTTP 请求
+func (b *BaseClient) do(ctx context.Context, req *APIRequest, fullURL string, bodyBytes []byte) (*APIResponse, error) {
+	var body io.Reader
+	if bodyBytes != nil {
 		body = bytes.NewReader(bodyBytes)
 	}
 	
+	// 单个请求的超时通过 context 控制，未指定时使用 API 源配置的 timeout
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = durationOr(b.timeout, defaultRequestTimeout)
+	}
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
 	// 创建HTTP请求
 	httpReq, err := http.NewRequestWithContext(ctx, req.Method, fullURL, body)
 	if err != nil {
@@ -74,15 +160,9 @@ func (b *BaseClient) Request(ctx context.Context, req *APIRequest) (*APIResponse
 		httpReq.Header.Set(key, value)
 	}
 	
-	// 设置超时
-	client := b.client
-	if req.Timeout > 0 {
-		client = &http.Client{Timeout: req.Timeout}
-	}
-	
 	// 发送请求
 	start := time.Now()
-	resp, err := client.Do(httpReq)
+	resp, err := b.client.Do(httpReq)
 	duration := time.Since(start)
 	
 	if err != nil {
@@ -120,10 +200,8 @@ func (b *BaseClient) buildURL(path string, params map[string]interface{}) (strin
 		return "", err
 	}
 	
-	fullURL, err := baseURL.Parse(path)
-	if err != nil {
-		return "", err
-	}
+	// 请求路径拼接在 base-url 的路径之后，如 https://gitee.com/api/v5 + /repos/owner/repo
+	fullURL := baseURL.JoinPath(path)
 	
 	// 添加查询参数
 	if len(params) > 0 {
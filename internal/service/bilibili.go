@@ -2,52 +2,87 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
-// BilibiliClient Bilibili客户端
+// bilibiliMaxPageSize 空间视频列表每页最多返回的视频数
+const bilibiliMaxPageSize = 50
+
+// BilibiliClient Bilibili客户端；空间等接口需要 WBI 签名和 buvid3 Cookie
 type BilibiliClient struct {
 	BaseClient
+	wbiKey     string     // 缓存的 WBI 混合密钥
+	wbiExpires time.Time
+	refresh    *Coalescer // 合并并发的密钥和 buvid3 获取请求
+	mu         sync.Mutex
 }
 
 func NewBilibiliClient(config APISourceConfig) *BilibiliClient {
+	client := newHTTPClient(config)
+	if client.Jar == nil {
+		client.Jar, _ = cookiejar.New(nil)
+	}
+
 	return &BilibiliClient{
 		BaseClient: BaseClient{
 			name:      "bilibili",
 			baseURL:   config.BaseURL,
 			timeout:   config.Timeout,
 			headers:   config.Headers,
-			client:    &http.Client{Timeout: config.Timeout},
+			client:    client,
+			retry:     config.Retry,
 		},
+		refresh: NewCoalescer(),
 	}
 }
 
-// GetUPMasterVideos 获取UP主视频
+// GetUPMasterVideos 按发布时间倒序获取UP主最新的 limit 个视频，超过一页时自动翻页
 func (b *BilibiliClient) GetUPMasterVideos(ctx context.Context, uid string, limit int) ([]BilibiliVideoInfo, error) {
-	req := &APIRequest{
-		Method: "GET",
-		Path:   "/x/space/arc/search",
-		Params: map[string]interface{}{
-			"mid":     uid,
-			"ps":      limit,
-			"tid":     0,
-			"pn":      1,
-			"keyword": "",
-			"order":   "pubdate",
-		},
-		Headers: map[string]string{
-			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
-			"Referer":    "https://www.bilibili.com",
-		},
-		Timeout: 10 * time.Second,
+	pageSize := limit
+	if pageSize <= 0 || pageSize > bilibiliMaxPageSize {
+		pageSize = bilibiliMaxPageSize
+	}
+	
+	var videos []BilibiliVideoInfo
+	for page := 1; limit <= 0 || len(videos) < limit; page++ {
+		vlist, count, err := b.upMasterVideosPage(ctx, uid, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		videos = append(videos, vlist...)
+		if len(vlist) < pageSize || page*pageSize >= count || limit <= 0 {
+			break
+		}
 	}
 	
-	resp, err := b.Request(ctx, req)
+	if limit > 0 && len(videos) > limit {
+		videos = videos[:limit]
+	}
+	return videos, nil
+}
+
+// upMasterVideosPage 获取UP主视频列表的一页，同时返回视频总数
+func (b *BilibiliClient) upMasterVideosPage(ctx context.Context, uid string, page, pageSize int) ([]BilibiliVideoInfo, int, error) {
+	params, err := b.signParams(ctx, map[string]interface{}{
+		"mid":     uid,
+		"ps":      pageSize,
+		"tid":     0,
+		"pn":      page,
+		"keyword": "",
+		"order":   "pubdate",
+	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	
 	var apiResp struct {
@@ -57,28 +92,231 @@ func (b *BilibiliClient) GetUPMasterVideos(ctx context.Context, uid string, limi
 			List struct {
 				Vlist []BilibiliVideoInfo `json:"vlist"`
 			} `json:"list"`
+			Page struct {
+				Count int `json:"count"`
+			} `json:"page"`
 		} `json:"data"`
 	}
 	
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, err
+	if err := b.get(ctx, "/x/space/wbi/arc/search", params, &apiResp); err != nil {
+		return nil, 0, err
 	}
 	
+	if apiResp.Code != 0 {
+		// -352 表示签名校验失败，密钥可能已更换
+		if apiResp.Code == -352 {
+			b.resetWBIKey()
+		}
+		return nil, 0, fmt.Errorf("bilibili API error: %s", apiResp.Message)
+	}
+	
+	return apiResp.Data.List.Vlist, apiResp.Data.Page.Count, nil
+}
+
+// GetVideoStat 获取视频的播放、点赞、投币、收藏等统计数据
+func (b *BilibiliClient) GetVideoStat(ctx context.Context, bvid string) (*BilibiliVideoStat, error) {
+	var apiResp struct {
+		Code    int               `json:"code"`
+		Message string            `json:"message"`
+		Data    BilibiliVideoStat `json:"data"`
+	}
+	
+	if err := b.get(ctx, "/x/web-interface/archive/stat", map[string]interface{}{"bvid": bvid}, &apiResp); err != nil {
+		return nil, err
+	}
 	if apiResp.Code != 0 {
 		return nil, fmt.Errorf("bilibili API error: %s", apiResp.Message)
 	}
 	
-	return apiResp.Data.List.Vlist, nil
+	return &apiResp.Data, nil
+}
+
+// signParams 确保会话带有 buvid3 Cookie，并为参数加上 WBI 签名
+func (b *BilibiliClient) signParams(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	if err := b.ensureBuvid(ctx); err != nil {
+		return nil, err
+	}
+	
+	key, err := b.mixinKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	return SignWBIParams(params, key, time.Now()), nil
+}
+
+// mixinKey 返回缓存的 WBI 密钥，过期或被清除时重新获取；获取期间不持有锁，并发的获取请求合并为一次
+func (b *BilibiliClient) mixinKey(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	key, expires := b.wbiKey, b.wbiExpires
+	b.mu.Unlock()
+	
+	if key != "" && time.Now().Before(expires) {
+		return key, nil
+	}
+	
+	value, _, err := b.refresh.Do(ctx, "wbi", func(ctx context.Context) (interface{}, error) {
+		key, err := b.fetchWBIKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		
+		b.mu.Lock()
+		b.wbiKey, b.wbiExpires = key, time.Now().Add(wbiKeyTTL)
+		b.mu.Unlock()
+		return key, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
+
+// resetWBIKey 清除缓存的 WBI 密钥，下次请求时重新获取
+func (b *BilibiliClient) resetWBIKey() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	b.wbiKey = ""
+}
+
+// fetchWBIKey 从 nav 接口获取 WBI 密钥；未登录时接口返回 code -101，但仍包含 wbi_img
+func (b *BilibiliClient) fetchWBIKey(ctx context.Context) (string, error) {
+	var nav struct {
+		Message string `json:"message"`
+		Data    struct {
+			WbiImg struct {
+				ImgURL string `json:"img_url"`
+				SubURL string `json:"sub_url"`
+			} `json:"wbi_img"`
+		} `json:"data"`
+	}
+	
+	if err := b.get(ctx, "/x/web-interface/nav", nil, &nav); err != nil {
+		return "", err
+	}
+	if nav.Data.WbiImg.ImgURL == "" || nav.Data.WbiImg.SubURL == "" {
+		return "", fmt.Errorf("bilibili nav returned no wbi keys: %s", nav.Message)
+	}
+	
+	return WBIMixinKey(nav.Data.WbiImg.ImgURL, nav.Data.WbiImg.SubURL), nil
+}
+
+// ensureBuvid Cookie Jar 中没有 buvid3 时从 spi 接口获取，接口不可用时在本地生成；并发的获取请求合并为一次
+func (b *BilibiliClient) ensureBuvid(ctx context.Context) error {
+	target, err := url.Parse(b.baseURL)
+	if err != nil {
+		return err
+	}
+	if hasCookie(b.client.Jar, target, "buvid3") {
+		return nil
+	}
+	
+	_, _, err = b.refresh.Do(ctx, "buvid3", func(ctx context.Context) (interface{}, error) {
+		return nil, b.fetchBuvid(ctx, target)
+	})
+	return err
+}
+
+// fetchBuvid 从 spi 接口获取 buvid3 并保存到 Cookie Jar
+func (b *BilibiliClient) fetchBuvid(ctx context.Context, target *url.URL) error {
+	if hasCookie(b.client.Jar, target, "buvid3") {
+		return nil
+	}
+	
+	var spi struct {
+		Data struct {
+			B3 string `json:"b_3"`
+			B4 string `json:"b_4"`
+		} `json:"data"`
+	}
+	if err := b.get(ctx, "/x/frontend/finger/spi", nil, &spi); err != nil || spi.Data.B3 == "" {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("failed to fetch bilibili buvid3, generating locally: %v", err)
+		spi.Data.B3 = localBuvid()
+	}
+	
+	cookies := []*http.Cookie{{Name: "buvid3", Value: spi.Data.B3, Path: "/"}}
+	if spi.Data.B4 != "" {
+		cookies = append(cookies, &http.Cookie{Name: "buvid4", Value: spi.Data.B4, Path: "/"})
+	}
+	b.client.Jar.SetCookies(target, cookies)
+	return nil
 }
 
+func hasCookie(jar http.CookieJar, target *url.URL, name string) bool {
+	for _, cookie := range jar.Cookies(target) {
+		if cookie.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// localBuvid 生成与浏览器格式一致的 buvid3：大写 UUID 后接 5 位数字和 infoc
+func localBuvid() string {
+	var random [18]byte
+	rand.Read(random[:])
+	r := random[:16]
+	return fmt.Sprintf("%X-%X-%X-%X-%X%05dinfoc", r[0:4], r[4:6], r[6:8], r[8:10], r[10:16], (int(random[16])<<8|int(random[17]))%100000)
+}
+
+// get 请求 Bilibili API 并解析响应体
+func (b *BilibiliClient) get(ctx context.Context, path string, params map[string]interface{}, dest interface{}) error {
+	req := &APIRequest{
+		Method: "GET",
+		Path:   path,
+		Params: params,
+		Headers: map[string]string{
+			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+			"Referer":    "https://www.bilibili.com",
+		},
+	}
+	
+	resp, err := b.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	
+	return json.Unmarshal(resp.Body, dest)
+}
+
+// BilibiliVideoInfo 空间视频列表中的视频
 type BilibiliVideoInfo struct {
 	Aid         int64  `json:"aid"`
+	Bvid        string `json:"bvid"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Author      string `json:"author"`
 	Mid         int64  `json:"mid"`
 	Created     int64  `json:"created"`
 	Length      string `json:"length"`
-	VideoReview int64  `json:"video_review"`
+	VideoReview int64  `json:"video_review"` // 弹幕数
+	Play        BilibiliCount `json:"play"`
+	Comment     int64  `json:"comment"`
 	Pic         string `json:"pic"`
 }
+
+// BilibiliVideoStat 视频统计数据
+type BilibiliVideoStat struct {
+	Aid      int64  `json:"aid"`
+	Bvid     string `json:"bvid"`
+	View     int64  `json:"view"`
+	Danmaku  int64  `json:"danmaku"`
+	Reply    int64  `json:"reply"`
+	Favorite int64  `json:"favorite"`
+	Coin     int64  `json:"coin"`
+	Share    int64  `json:"share"`
+	Like     int64  `json:"like"`
+}
+
+// BilibiliCount 计数字段，UP主隐藏播放量时接口返回字符串 "--"，按 0 处理
+type BilibiliCount int64
+
+func (c *BilibiliCount) UnmarshalJSON(data []byte) error {
+	value, _ := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	*c = BilibiliCount(value)
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送到上游
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行请求
+	BreakerOpen     BreakerState = "open"      // 直接拒绝请求，冷却结束后进入半开
+	BreakerHalfOpen BreakerState = "half-open" // 放行少量探测请求，成功后关闭，失败后重新打开
+)
+
+// CircuitBreakerConfig API 源的熔断配置
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `yaml:"failure-threshold"`  // 连续失败多少次后打开，默认 5，小于 0 表示不启用
+	CoolDown         time.Duration `yaml:"cool-down"`          // 打开后多久进入半开状态，默认 30s
+	HalfOpenRequests int           `yaml:"half-open-requests"` // 半开状态下同时放行的探测请求数，默认 1
+}
+
+// BreakerStatus 熔断器的当前状态，用于状态页展示
+type BreakerStatus struct {
+	State    BreakerState `json:"state"`
+	Failures int          `json:"failures"`            // 连续失败次数
+	OpenedAt time.Time    `json:"opened_at,omitempty"` // 最近一次打开的时间
+	RetryAt  time.Time    `json:"retry_at,omitempty"`  // 打开状态下进入半开的时间
+}
+
+// circuitBreaker 单个 API 源的熔断器
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+	mu       sync.Mutex
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = 5
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+
+	return &circuitBreaker{config: config, state: BreakerClosed}
+}
+
+// allow 判断是否放行请求；放行的请求必须调用 done 或 release
+func (b *circuitBreaker) allow() bool {
+	if b.config.FailureThreshold < 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.config.CoolDown {
+		b.state = BreakerHalfOpen
+		b.probes = 0
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// release 放行的请求未发送到上游（如被限流），归还半开状态下的探测名额
+func (b *circuitBreaker) release() {
+	b.done(context.Canceled)
+}
+
+// done 报告放行请求的结果
+func (b *circuitBreaker) done(err error) {
+	if b.config.FailureThreshold < 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	// 只有成功的请求才能关闭熔断器；调用方取消、限流信号和普通 4xx 不能说明上游是否恢复，不改变状态
+	if err == nil {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	if errors.Is(err, context.Canceled) || !isBreakerFailure(err) {
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// status 返回熔断器的当前状态
+func (b *circuitBreaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, Failures: b.failures, OpenedAt: b.openedAt}
+	if b.state == BreakerOpen {
+		status.RetryAt = b.openedAt.Add(b.config.CoolDown)
+		if !time.Now().Before(status.RetryAt) {
+			status.State = BreakerHalfOpen
+		}
+	}
+	return status
+}
+
+// isBreakerFailure 判断错误是否说明上游不可用：网络错误、超时、429 和 5xx 计入失败，
+// 其他 4xx 说明上游可以正常响应；被识别为限流信号的响应由自适应限流处理，不计入失败
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, ErrUpstreamThrottled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// circuitOpenError 返回熔断打开时的错误
+func circuitOpenError(serviceName string) error {
+	return fmt.Errorf("%s: %w", serviceName, ErrCircuitOpen)
+}
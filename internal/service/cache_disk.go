@@ -0,0 +1,389 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDiskCacheDir = "data/cache"
+	diskCacheExt        = ".json"
+	// diskCompactInterval 后台清理过期条目的间隔
+	diskCompactInterval = 5 * time.Minute
+)
+
+// DiskCache 磁盘缓存实现，容器重启后缓存仍然有效
+type DiskCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	index     map[string]*diskEntry // 文件名 -> 条目信息
+	size      int64
+	evictions int64
+	mu        sync.Mutex
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+type diskEntry struct {
+	size       int64
+	expiredAt  time.Time
+	accessedAt time.Time
+}
+
+// diskRecord 缓存文件内容，包含原始键和过期时间
+type diskRecord struct {
+	Key       string          `json:"key"`
+	ExpiredAt time.Time       `json:"expired_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// NewDiskCache 创建磁盘缓存，并从缓存目录恢复已有条目
+func NewDiskCache(config CacheConfig) (*DiskCache, error) {
+	dir := config.Dir
+	if dir == "" {
+		dir = defaultDiskCacheDir
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	d := &DiskCache{
+		dir:     dir,
+		ttl:     config.TTL,
+		maxSize: int64(config.MaxSize),
+		index:   make(map[string]*diskEntry),
+		stopCh:  make(chan struct{}),
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	// 启动压缩协程
+	go d.compactLoop()
+
+	return d, nil
+}
+
+func (d *DiskCache) Get(ctx context.Context, key string, dest interface{}) error {
+	name := diskFileName(key)
+
+	data, err := os.ReadFile(filepath.Join(d.dir, name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	var record diskRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Key != key {
+		// 损坏的文件直接丢弃
+		d.removeStale(name, data)
+		return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+	}
+
+	if !record.ExpiredAt.IsZero() && time.Now().After(record.ExpiredAt) {
+		d.removeStale(name, data)
+		return fmt.Errorf("%w: %s (expired)", ErrCacheMiss, key)
+	}
+
+	d.mu.Lock()
+	if entry, exists := d.index[name]; exists {
+		entry.accessedAt = time.Now()
+	}
+	d.mu.Unlock()
+
+	return json.Unmarshal(record.Value, dest)
+}
+
+func (d *DiskCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		ttl = d.ttl
+	}
+
+	record := diskRecord{Key: key, Value: valueBytes}
+	if ttl > 0 {
+		record.ExpiredAt = time.Now().Add(ttl)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(data))
+	if d.maxSize > 0 && size > d.maxSize {
+		return fmt.Errorf("cache value too large: %s (%d bytes)", key, size)
+	}
+
+	name := diskFileName(key)
+	tmpName, err := d.writeTemp(name, data)
+	if err != nil {
+		return err
+	}
+
+	// 替换文件和更新索引在同一把锁内完成，Get 清理旧文件时不会删掉刚写入的文件
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.Rename(tmpName, filepath.Join(d.dir, name)); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if old, exists := d.index[name]; exists {
+		d.size -= old.size
+	}
+	d.index[name] = &diskEntry{
+		size:       size,
+		expiredAt:  record.ExpiredAt,
+		accessedAt: time.Now(),
+	}
+	d.size += size
+
+	d.enforceLimit(name)
+
+	return nil
+}
+
+func (d *DiskCache) Delete(ctx context.Context, key string) error {
+	d.remove(diskFileName(key))
+	return nil
+}
+
+func (d *DiskCache) Clear(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, entry := range d.index {
+		if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		d.size -= entry.size
+		delete(d.index, name)
+	}
+
+	return nil
+}
+
+// Close 停止后台压缩
+func (d *DiskCache) Close() error {
+	d.once.Do(func() { close(d.stopCh) })
+	return nil
+}
+
+// Size 当前缓存占用的字节数
+func (d *DiskCache) Size() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.size
+}
+
+// Evictions 因容量不足被淘汰的条目数
+func (d *DiskCache) Evictions() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.evictions
+}
+
+// writeTemp 把数据写入临时文件并返回文件路径；调用方再重命名为缓存文件，保证读取方不会看到写了一半的文件
+func (d *DiskCache) writeTemp(name string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(d.dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	return tmpName, nil
+}
+
+func (d *DiskCache) remove(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.removeLocked(name)
+}
+
+// removeStale 删除 Get 读到的损坏或过期文件；加锁后重新读取，文件已被 Set 替换时保留
+func (d *DiskCache) removeStale(name string, data []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current, err := os.ReadFile(filepath.Join(d.dir, name))
+	if err != nil || !bytes.Equal(current, data) {
+		return
+	}
+	d.removeLocked(name)
+}
+
+func (d *DiskCache) removeLocked(name string) {
+	if err := os.Remove(filepath.Join(d.dir, name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("failed to remove cache file %s: %v", name, err)
+	}
+	if entry, exists := d.index[name]; exists {
+		d.size -= entry.size
+		delete(d.index, name)
+	}
+}
+
+// enforceLimit 超出容量时先清理过期条目，再按最近访问时间淘汰，keep 为刚写入的条目
+func (d *DiskCache) enforceLimit(keep string) {
+	if d.maxSize <= 0 || d.size <= d.maxSize {
+		return
+	}
+
+	now := time.Now()
+	for name, entry := range d.index {
+		if !entry.expiredAt.IsZero() && now.After(entry.expiredAt) {
+			d.removeLocked(name)
+		}
+	}
+
+	if d.size <= d.maxSize {
+		return
+	}
+
+	names := make([]string, 0, len(d.index))
+	for name := range d.index {
+		if name != keep {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return d.index[names[i]].accessedAt.Before(d.index[names[j]].accessedAt)
+	})
+
+	for _, name := range names {
+		if d.size <= d.maxSize {
+			break
+		}
+		d.removeLocked(name)
+		d.evictions++
+	}
+}
+
+// load 扫描缓存目录重建索引，清理过期条目和残留的临时文件
+func (d *DiskCache) load() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache dir: %w", err)
+	}
+
+	now := time.Now()
+	for _, dirEntry := range entries {
+		name := dirEntry.Name()
+		path := filepath.Join(d.dir, name)
+
+		if dirEntry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(path)
+			continue
+		}
+		if !strings.HasSuffix(name, diskCacheExt) {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var record diskRecord
+		if err := json.Unmarshal(data, &record); err != nil || diskFileName(record.Key) != name {
+			os.Remove(path)
+			continue
+		}
+		if !record.ExpiredAt.IsZero() && now.After(record.ExpiredAt) {
+			os.Remove(path)
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		d.index[name] = &diskEntry{
+			size:       int64(len(data)),
+			expiredAt:  record.ExpiredAt,
+			accessedAt: info.ModTime(),
+		}
+		d.size += int64(len(data))
+	}
+
+	d.mu.Lock()
+	d.enforceLimit("")
+	d.mu.Unlock()
+
+	return nil
+}
+
+func (d *DiskCache) compactLoop() {
+	ticker := time.NewTicker(diskCompactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.compact()
+		}
+	}
+}
+
+// compact 删除过期条目
+func (d *DiskCache) compact() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for name, entry := range d.index {
+		if !entry.expiredAt.IsZero() && now.After(entry.expiredAt) {
+			d.removeLocked(name)
+		}
+	}
+}
+
+// diskFileName 由缓存键生成文件名，避免键中的特殊字符
+func diskFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + diskCacheExt
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	evictionLRU     = "lru"
+	evictionTinyLFU = "tinylfu"
+	// tinyLFUWindowPercent W-TinyLFU 窗口区占总容量的百分比
+	tinyLFUWindowPercent = 1
+)
+
+// MemoryCache 内存缓存实现，超出 MaxSize 时按 LRU 或 W-TinyLFU 策略淘汰
+type MemoryCache struct {
+	items map[string]*list.Element
+	// main 按访问顺序排列，队首为最近访问
+	main     *list.List
+	mainSize int64
+	// window W-TinyLFU 的窗口区，新条目先进入窗口区，被挤出时再与 main 的淘汰候选比较访问频率
+	window     *list.List
+	windowSize int64
+	windowMax  int64
+	sketch     *countMinSketch // 为 nil 时使用 LRU
+
+	maxSize   int64
+	ttl       time.Duration
+	evictions int64
+	mu        sync.Mutex
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+type cacheItem struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+	inWindow  bool
+}
+
+func (i *cacheItem) size() int64 {
+	return int64(len(i.key) + len(i.value))
+}
+
+func (i *cacheItem) expired(now time.Time) bool {
+	return !i.expiredAt.IsZero() && now.After(i.expiredAt)
+}
+
+func NewMemoryCache(config CacheConfig) *MemoryCache {
+	cache := &MemoryCache{
+		items:   make(map[string]*list.Element),
+		main:    list.New(),
+		window:  list.New(),
+		maxSize: int64(config.MaxSize),
+		ttl:     config.TTL,
+		stopCh:  make(chan struct{}),
+	}
+
+	switch config.EvictionPolicy {
+	case "", evictionLRU:
+	case evictionTinyLFU:
+		cache.sketch = newCountMinSketch()
+		cache.windowMax = cache.maxSize * tinyLFUWindowPercent / 100
+	default:
+		log.Printf("unknown eviction policy %q, using %s", config.EvictionPolicy, evictionLRU)
+	}
+
+	// 启动清理协程
+	go cache.cleanup()
+
+	return cache
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	m.mu.Lock()
+
+	if m.sketch != nil {
+		m.sketch.increment(key)
+	}
+
+	elem, exists := m.items[key]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+	}
+
+	item := elem.Value.(*cacheItem)
+	if item.expired(time.Now()) {
+		m.removeElement(elem)
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s (expired)", ErrCacheMiss, key)
+	}
+
+	m.listOf(item).MoveToFront(elem)
+	value := item.value
+	m.mu.Unlock()
+
+	return json.Unmarshal(value, dest)
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		ttl = m.ttl
+	}
+
+	// ttl 为 0 时不过期
+	item := &cacheItem{key: key, value: data}
+	if ttl > 0 {
+		item.expiredAt = time.Now().Add(ttl)
+	}
+
+	if m.maxSize > 0 && item.size() > m.maxSize {
+		return fmt.Errorf("cache value too large: %s (%d bytes)", key, item.size())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sketch != nil {
+		m.sketch.increment(key)
+	}
+
+	if elem, exists := m.items[key]; exists {
+		// 覆盖已有条目，先扣除旧值的大小
+		old := elem.Value.(*cacheItem)
+		item.inWindow = old.inWindow
+		m.addSize(old, -old.size())
+		elem.Value = item
+		m.addSize(item, item.size())
+		m.listOf(item).MoveToFront(elem)
+	} else {
+		item.inWindow = m.sketch != nil
+		m.items[key] = m.listOf(item).PushFront(item)
+		m.addSize(item, item.size())
+	}
+
+	m.evict()
+
+	return nil
+}
+
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, exists := m.items[key]; exists {
+		m.removeElement(elem)
+	}
+
+	return nil
+}
+
+func (m *MemoryCache) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.main.Init()
+	m.window.Init()
+	m.mainSize, m.windowSize = 0, 0
+	if m.sketch != nil {
+		m.sketch = newCountMinSketch()
+	}
+
+	return nil
+}
+
+// Close 停止后台清理
+func (m *MemoryCache) Close() error {
+	m.once.Do(func() { close(m.stopCh) })
+	return nil
+}
+
+// Size 当前缓存占用的字节数（键和值）
+func (m *MemoryCache) Size() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mainSize + m.windowSize
+}
+
+// Len 当前缓存条目数
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items)
+}
+
+// Evictions 因容量不足被淘汰的条目数，不含过期和主动删除
+func (m *MemoryCache) Evictions() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.evictions
+}
+
+func (m *MemoryCache) listOf(item *cacheItem) *list.List {
+	if item.inWindow {
+		return m.window
+	}
+	return m.main
+}
+
+func (m *MemoryCache) addSize(item *cacheItem, delta int64) {
+	if item.inWindow {
+		m.windowSize += delta
+	} else {
+		m.mainSize += delta
+	}
+}
+
+func (m *MemoryCache) removeElement(elem *list.Element) {
+	item := elem.Value.(*cacheItem)
+	m.listOf(item).Remove(elem)
+	m.addSize(item, -item.size())
+	delete(m.items, item.key)
+}
+
+// evict 淘汰条目直到总大小不超过 maxSize
+func (m *MemoryCache) evict() {
+	if m.maxSize <= 0 {
+		return
+	}
+
+	if m.sketch == nil {
+		// 刚写入的条目位于队首且不超过 maxSize，不会被淘汰
+		for m.mainSize > m.maxSize {
+			m.removeElement(m.main.Back())
+			m.evictions++
+		}
+		return
+	}
+
+	mainMax := m.maxSize - m.windowMax
+	for m.windowSize > m.windowMax {
+		elem := m.window.Back()
+		candidate := elem.Value.(*cacheItem)
+		m.removeElement(elem)
+		m.admit(candidate, mainMax)
+	}
+
+	// 覆盖写入可能使 main 超出容量
+	for m.mainSize > mainMax && m.main.Len() > 1 {
+		m.removeElement(m.main.Back())
+		m.evictions++
+	}
+}
+
+// admit 窗口区挤出的候选条目只有访问频率高于 main 的淘汰候选时才会进入 main
+func (m *MemoryCache) admit(candidate *cacheItem, mainMax int64) {
+	frequency := m.sketch.estimate(candidate.key)
+
+	for m.mainSize+candidate.size() > mainMax {
+		elem := m.main.Back()
+		if elem == nil {
+			break
+		}
+
+		victim := elem.Value.(*cacheItem)
+		if frequency <= m.sketch.estimate(victim.key) {
+			m.evictions++
+			return
+		}
+
+		m.removeElement(elem)
+		m.evictions++
+	}
+
+	candidate.inWindow = false
+	m.items[candidate.key] = m.main.PushFront(candidate)
+	m.mainSize += candidate.size()
+}
+
+func (m *MemoryCache) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.removeExpired()
+		}
+	}
+}
+
+func (m *MemoryCache) removeExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, elem := range m.items {
+		if elem.Value.(*cacheItem).expired(now) {
+			m.removeElement(elem)
+		}
+	}
+}
+
+const (
+	sketchDepth = 4
+	sketchWidth = 1 << 14
+	// sketchMaxCount 计数器上限（4 位计数器）
+	sketchMaxCount = 15
+)
+
+// countMinSketch 近似记录键的访问频率，累计一定次数后所有计数减半，使频率随时间衰减
+type countMinSketch struct {
+	rows      [sketchDepth][sketchWidth]uint8
+	additions int
+	resetAt   int
+}
+
+func newCountMinSketch() *countMinSketch {
+	return &countMinSketch{resetAt: sketchWidth * 10}
+}
+
+func (s *countMinSketch) indexes(key string) [sketchDepth]uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+
+	h1, h2 := uint32(sum), uint32(sum>>32)
+	var indexes [sketchDepth]uint32
+	for i := range indexes {
+		indexes[i] = (h1 + uint32(i)*h2) & (sketchWidth - 1)
+	}
+	return indexes
+}
+
+func (s *countMinSketch) increment(key string) {
+	for i, index := range s.indexes(key) {
+		if s.rows[i][index] < sketchMaxCount {
+			s.rows[i][index]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	min := uint8(sketchMaxCount)
+	for i, index := range s.indexes(key) {
+		if s.rows[i][index] < min {
+			min = s.rows[i][index]
+		}
+	}
+	return min
+}
+
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRedisURL    = "redis://localhost:6379"
+	defaultRedisPrefix = "glance:"
+	// redisScanCount Clear 时每次 SCAN 的建议返回数量
+	redisScanCount = 500
+)
+
+// RedisCache Redis缓存实现
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCache 根据 CacheConfig.RedisURL 创建Redis缓存
+func NewRedisCache(config CacheConfig) (*RedisCache, error) {
+	redisURL := config.RedisURL
+	if redisURL == "" {
+		redisURL = defaultRedisURL
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	return NewRedisCacheWithClient(redis.NewClient(options), config), nil
+}
+
+// NewRedisCacheWithClient 使用已有的Redis客户端创建缓存
+func NewRedisCacheWithClient(client redis.UniversalClient, config CacheConfig) *RedisCache {
+	prefix := config.RedisPrefix
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+		ttl:    config.TTL,
+	}
+}
+
+func (r *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		ttl = r.ttl
+	}
+
+	// ttl 为 0 时不过期
+	return r.client.Set(ctx, r.prefix+key, data, ttl).Err()
+}
+
+func (r *RedisCache) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Clear 通过 SCAN 删除本缓存前缀下的键，不影响同一Redis中的其他数据
+func (r *RedisCache) Clear(ctx context.Context) error {
+	pattern := escapeRedisPattern(r.prefix) + "*"
+
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, redisScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Ping 检查Redis连接
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// IsHealthy Redis连接是否可用
+func (r *RedisCache) IsHealthy(ctx context.Context) bool {
+	return r.Ping(ctx) == nil
+}
+
+// PoolStats 获取连接池统计
+func (r *RedisCache) PoolStats() *redis.PoolStats {
+	return r.client.PoolStats()
+}
+
+// Close 关闭Redis连接
+func (r *RedisCache) Close() error {
+	return r.client.Close()
+}
+
+// escapeRedisPattern 转义 SCAN MATCH 中的通配字符
+func escapeRedisPattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
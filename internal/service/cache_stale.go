@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// staleRefreshTimeout 后台刷新的超时时间，与触发刷新的请求无关
+const staleRefreshTimeout = 30 * time.Second
+
+// LoadFunc 缓存未命中或需要刷新时获取最新数据
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// CacheStatus 一次读取的缓存状态
+type CacheStatus struct {
+	Stale      bool      // 返回的是过期数据，后台正在刷新
+	StaleSince time.Time // 数据开始过期的时间
+}
+
+// StaleCache 在 CacheManager 之上实现 stale-while-revalidate：
+// 条目过期后在宽限期内继续返回旧数据，同时在后台刷新
+type StaleCache struct {
+	cache      CacheManager
+	grace      time.Duration
+	refreshing map[string]struct{}
+	inflight   *Coalescer
+	mu         sync.Mutex
+}
+
+// staleEntry 缓存中保存的内容，FreshUntil 之后视为过期数据
+type staleEntry struct {
+	Value      json.RawMessage `json:"value"`
+	FreshUntil time.Time       `json:"fresh_until"`
+}
+
+// NewStaleCache 创建 StaleCache，grace 为过期后仍可返回旧数据的时长，0 表示不启用
+func NewStaleCache(cache CacheManager, grace time.Duration) *StaleCache {
+	return &StaleCache{
+		cache:      cache,
+		grace:      grace,
+		refreshing: make(map[string]struct{}),
+		inflight:   NewCoalescer(),
+	}
+}
+
+// Fetch 读取 key 对应的数据到 dest；未命中时同步调用 load，数据过期但仍在宽限期内时立即返回旧数据并在后台刷新
+func (s *StaleCache) Fetch(ctx context.Context, key string, ttl time.Duration, dest interface{}, load LoadFunc) (CacheStatus, error) {
+	var entry staleEntry
+	err := s.cache.Get(ctx, key, &entry)
+	if err == nil {
+		if err := json.Unmarshal(entry.Value, dest); err != nil {
+			return CacheStatus{}, err
+		}
+
+		if time.Now().Before(entry.FreshUntil) {
+			return CacheStatus{}, nil
+		}
+
+		s.refresh(ctx, key, ttl, load)
+		return CacheStatus{Stale: true, StaleSince: entry.FreshUntil}, nil
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		log.Printf("failed to read cache %s: %v", key, err)
+	}
+
+	value, err := s.load(ctx, key, ttl, load)
+	if err != nil {
+		return CacheStatus{}, err
+	}
+
+	return CacheStatus{}, json.Unmarshal(value, dest)
+}
+
+// Refresh 立即获取最新数据并写入缓存，用于在数据过期前预热
+func (s *StaleCache) Refresh(ctx context.Context, key string, ttl time.Duration, load LoadFunc) error {
+	_, err := s.load(ctx, key, ttl, load)
+	return err
+}
+
+// load 获取数据并写入缓存，缓存保留时长为 ttl 加宽限期；同一 key 的并发加载合并为一次
+func (s *StaleCache) load(ctx context.Context, key string, ttl time.Duration, load LoadFunc) (json.RawMessage, error) {
+	value, _, err := s.inflight.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := staleEntry{Value: data, FreshUntil: time.Now().Add(ttl)}
+		if err := s.cache.Set(ctx, key, entry, ttl+s.grace); err != nil {
+			log.Printf("failed to cache %s: %v", key, err)
+		}
+
+		return json.RawMessage(data), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(json.RawMessage), nil
+}
+
+// Coalesced 返回与其他调用合并、未单独回源的加载次数
+func (s *StaleCache) Coalesced() int64 {
+	return s.inflight.Coalesced()
+}
+
+// refresh 在后台刷新过期数据，同一个 key 同时只有一个刷新任务
+func (s *StaleCache) refresh(ctx context.Context, key string, ttl time.Duration, load LoadFunc) {
+	s.mu.Lock()
+	if _, exists := s.refreshing[key]; exists {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing[key] = struct{}{}
+	s.mu.Unlock()
+
+	// 保留请求上下文中的值（如 serviceManager、locale），但不随请求结束而取消；后台刷新超出限流配额时排队等待
+	ctx, cancel := context.WithTimeout(WithRateLimitWait(context.WithoutCancel(ctx)), staleRefreshTimeout)
+
+	go func() {
+		defer cancel()
+		defer func() {
+			s.mu.Lock()
+			delete(s.refreshing, key)
+			s.mu.Unlock()
+		}()
+
+		if _, err := s.load(ctx, key, ttl, load); err != nil {
+			log.Printf("failed to refresh stale cache %s: %v", key, err)
+		}
+	}()
+}
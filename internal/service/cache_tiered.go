@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCacheTiers 多级缓存默认层级，按从快到慢排列
+var defaultCacheTiers = []string{"memory", "disk", "redis"}
+
+// TieredCache 多级缓存，读取时逐级查找并将命中结果提升到更快的层级，写入时同时写入所有层级
+type TieredCache struct {
+	tiers  []*cacheTier
+	ttl    time.Duration
+	hits   int64
+	misses int64
+}
+
+type cacheTier struct {
+	name   string
+	cache  CacheManager
+	hits   int64
+	misses int64
+}
+
+// tieredEntry 各层级中保存的内容，记录过期时间以便提升时保留剩余TTL
+type tieredEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiredAt time.Time       `json:"expired_at,omitempty"`
+}
+
+// CacheTierStats 单个缓存层级的命中统计
+type CacheTierStats struct {
+	Name   string `json:"name"`
+	Hits   int64  `json:"hits"`
+	Misses int64  `json:"misses"`
+}
+
+// NewTieredCache 根据 CacheConfig.Tiers 创建多级缓存，无法创建的层级会被跳过
+func NewTieredCache(config CacheConfig) (*TieredCache, error) {
+	names := config.Tiers
+	if len(names) == 0 {
+		names = defaultCacheTiers
+	}
+
+	t := &TieredCache{ttl: config.TTL}
+	for _, name := range names {
+		cache, err := newCacheTier(name, config)
+		if err != nil {
+			log.Printf("failed to create %s cache tier, skipping: %v", name, err)
+			continue
+		}
+		t.tiers = append(t.tiers, &cacheTier{name: name, cache: cache})
+	}
+
+	if len(t.tiers) == 0 {
+		return nil, fmt.Errorf("no cache tiers available")
+	}
+
+	return t, nil
+}
+
+// NewTieredCacheWithTiers 使用已有的缓存创建多级缓存，names 与 caches 一一对应
+func NewTieredCacheWithTiers(config CacheConfig, names []string, caches []CacheManager) *TieredCache {
+	t := &TieredCache{ttl: config.TTL}
+	for i, cache := range caches {
+		t.tiers = append(t.tiers, &cacheTier{name: names[i], cache: cache})
+	}
+	return t
+}
+
+func newCacheTier(name string, config CacheConfig) (CacheManager, error) {
+	switch name {
+	case "memory":
+		return NewMemoryCache(config), nil
+	case "disk":
+		return NewDiskCache(config)
+	case "redis":
+		return NewRedisCache(config)
+	default:
+		return nil, fmt.Errorf("unknown cache tier: %s", name)
+	}
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	for i, tier := range t.tiers {
+		var entry tieredEntry
+		err := tier.cache.Get(ctx, key, &entry)
+		if err != nil {
+			// 层级不可用时按未命中处理，继续查找下一级
+			if !errors.Is(err, ErrCacheMiss) {
+				log.Printf("cache tier %s get %s failed: %v", tier.name, key, err)
+			}
+			atomic.AddInt64(&tier.misses, 1)
+			continue
+		}
+
+		if !entry.ExpiredAt.IsZero() && time.Now().After(entry.ExpiredAt) {
+			atomic.AddInt64(&tier.misses, 1)
+			continue
+		}
+
+		atomic.AddInt64(&tier.hits, 1)
+		atomic.AddInt64(&t.hits, 1)
+
+		t.promote(ctx, key, entry, t.tiers[:i])
+
+		return json.Unmarshal(entry.Value, dest)
+	}
+
+	atomic.AddInt64(&t.misses, 1)
+	return fmt.Errorf("%w: %s", ErrCacheMiss, key)
+}
+
+// promote 将命中结果写入更快的层级
+func (t *TieredCache) promote(ctx context.Context, key string, entry tieredEntry, tiers []*cacheTier) {
+	var ttl time.Duration
+	if !entry.ExpiredAt.IsZero() {
+		ttl = time.Until(entry.ExpiredAt)
+		if ttl <= 0 {
+			return
+		}
+	}
+
+	for _, tier := range tiers {
+		if err := tier.cache.Set(ctx, key, entry, ttl); err != nil {
+			log.Printf("cache tier %s promote %s failed: %v", tier.name, key, err)
+		}
+	}
+}
+
+func (t *TieredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		ttl = t.ttl
+	}
+
+	entry := tieredEntry{Value: data}
+	if ttl > 0 {
+		entry.ExpiredAt = time.Now().Add(ttl)
+	}
+
+	var errs []error
+	for _, tier := range t.tiers {
+		if err := tier.cache.Set(ctx, key, entry, ttl); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tier.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	var errs []error
+	for _, tier := range t.tiers {
+		if err := tier.cache.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tier.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *TieredCache) Clear(ctx context.Context) error {
+	var errs []error
+	for _, tier := range t.tiers {
+		if err := tier.cache.Clear(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tier.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsHealthy 所有支持健康检查的层级均可用时返回 true
+func (t *TieredCache) IsHealthy(ctx context.Context) bool {
+	for _, tier := range t.tiers {
+		if checker, ok := tier.cache.(interface{ IsHealthy(context.Context) bool }); ok && !checker.IsHealthy(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats 返回整体命中数、未命中数（所有层级均未命中）
+func (t *TieredCache) Stats() (hits, misses int64) {
+	return atomic.LoadInt64(&t.hits), atomic.LoadInt64(&t.misses)
+}
+
+// TierStats 返回各层级的命中统计，按查找顺序排列
+func (t *TieredCache) TierStats() []CacheTierStats {
+	stats := make([]CacheTierStats, len(t.tiers))
+	for i, tier := range t.tiers {
+		stats[i] = CacheTierStats{
+			Name:   tier.name,
+			Hits:   atomic.LoadInt64(&tier.hits),
+			Misses: atomic.LoadInt64(&tier.misses),
+		}
+	}
+	return stats
+}
+
+// Evictions 各层级因容量不足淘汰的条目总数
+func (t *TieredCache) Evictions() int64 {
+	var total int64
+	for _, tier := range t.tiers {
+		if counter, ok := tier.cache.(evictionCounter); ok {
+			total += counter.Evictions()
+		}
+	}
+	return total
+}
+
+// Close 关闭支持关闭的层级
+func (t *TieredCache) Close() error {
+	var errs []error
+	for _, tier := range t.tiers {
+		if closer, ok := tier.cache.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", tier.name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
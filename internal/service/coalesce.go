@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// errCallAborted 执行中的调用异常退出（如 panic）时等待方收到的错误
+var errCallAborted = errors.New("coalesced call aborted")
+
+// Coalescer 合并同一 key 的并发调用：同一时刻只有一次调用真正执行，其余调用方等待并共享它的结果
+type Coalescer struct {
+	calls     map[string]*coalescedCall
+	coalesced int64
+	mu        sync.Mutex
+}
+
+type coalescedCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewCoalescer 创建请求合并器
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		calls: make(map[string]*coalescedCall),
+	}
+}
+
+// Do 执行 fn 并返回其结果；key 已有调用在执行时等待该调用完成并返回相同结果，shared 表示结果来自其他调用方。
+// 发起调用的请求被取消时，仍未取消的等待方会重新发起调用，而不是共享取消错误
+func (c *Coalescer) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (value interface{}, shared bool, err error) {
+	for {
+		c.mu.Lock()
+		call, exists := c.calls[key]
+		if !exists {
+			call = &coalescedCall{done: make(chan struct{}), err: errCallAborted}
+			c.calls[key] = call
+			c.mu.Unlock()
+
+			c.run(ctx, key, call, fn)
+			return call.value, false, call.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+
+		if isContextError(call.err) && ctx.Err() == nil {
+			continue
+		}
+
+		atomic.AddInt64(&c.coalesced, 1)
+		return call.value, true, call.err
+	}
+}
+
+func (c *Coalescer) run(ctx context.Context, key string, call *coalescedCall, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn(ctx)
+}
+
+// Coalesced 返回共享其他调用结果、未实际执行的调用次数
+func (c *Coalescer) Coalesced() int64 {
+	return atomic.LoadInt64(&c.coalesced)
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// requestKey 生成 API 请求的合并 key；只有 GET、HEAD 等幂等请求参与合并，超时设置不影响 key。
+// 请求头中可能带有 token，key 取摘要后使用
+func requestKey(serviceName string, req *APIRequest) (string, bool) {
+	method := strings.ToUpper(req.Method)
+	if method != "" && method != "GET" && method != "HEAD" {
+		return "", false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%s", serviceName, method, req.Path)
+
+	params := make([]string, 0, len(req.Params))
+	for key, value := range req.Params {
+		params = append(params, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(params)
+	fmt.Fprintf(&b, "|%s", strings.Join(params, "&"))
+
+	headers := make([]string, 0, len(req.Headers))
+	for key, value := range req.Headers {
+		headers = append(headers, strings.ToLower(key)+":"+value)
+	}
+	sort.Strings(headers)
+	fmt.Fprintf(&b, "|%s", strings.Join(headers, "\n"))
+
+	if req.Body != nil {
+		body, err := json.Marshal(req.Body)
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintf(&b, "|%s", body)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:]), true
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"time"
 )
 
 // DouyuClient 斗鱼客户端
@@ -47,7 +45,8 @@ func NewDouyuClient(config APISourceConfig) *DouyuClient {
 			baseURL:   config.BaseURL,
 			timeout:   config.Timeout,
 			headers:   config.Headers,
-			client:    &http.Client{Timeout: config.Timeout},
+			client:    newHTTPClient(config),
+			retry:     config.Retry,
 		},
 	}
 }
@@ -74,10 +73,9 @@ func (d *DouyuClient) getRoomInfo(ctx context.Context, roomID string) (*DouyuStr
 		Headers: map[string]string{
 			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
 		},
-		Timeout: 5 * time.Second,
 	}
 	
-	resp, err := d.Request(ctx, req)
+	resp, err := d.send(ctx, req)
 	if err != nil {
 		return nil, err
 	}
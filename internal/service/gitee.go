@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GiteeClient Gitee客户端
+type GiteeClient struct {
+	BaseClient
+	token string
+}
+
+// GiteeUser Gitee 用户
+type GiteeUser struct {
+	Login   string `json:"login"`
+	Name    string `json:"name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// GiteeRepo Gitee 仓库
+type GiteeRepo struct {
+	Name            string    `json:"name"`
+	FullName        string    `json:"full_name"`
+	Description     string    `json:"description"`
+	HTMLURL         string    `json:"html_url"`
+	Language        string    `json:"language"`
+	StargazersCount int       `json:"stargazers_count"`
+	ForksCount      int       `json:"forks_count"`
+	OpenIssuesCount int       `json:"open_issues_count"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	PushedAt        time.Time `json:"pushed_at"`
+}
+
+// GiteeRelease Gitee 发行版
+type GiteeRelease struct {
+	ID         int64     `json:"id"`
+	TagName    string    `json:"tag_name"`
+	Name       string    `json:"name"`
+	HTMLURL    string    `json:"html_url"`
+	Prerelease bool      `json:"prerelease"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GiteeIssue Gitee Issue，编号为字符串形式（如 I4ABCD）
+type GiteeIssue struct {
+	Number    string    `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	User      GiteeUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GiteePullRequest Gitee Pull Request
+type GiteePullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	User      GiteeUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GiteeCommit Gitee 提交
+type GiteeCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// GiteeListOptions 列表接口的查询参数
+type GiteeListOptions struct {
+	State    string // open、closed、all，仅用于 Issue 和 Pull Request
+	PerPage  int    // 每页数量，默认 20，最大 100
+	MaxPages int    // 按 Link 响应头翻页时最多读取的页数，默认 1
+}
+
+func NewGiteeClient(config APISourceConfig) *GiteeClient {
+	return &GiteeClient{
+		BaseClient: BaseClient{
+			name:      "gitee",
+			baseURL:   config.BaseURL,
+			timeout:   config.Timeout,
+			headers:   config.Headers,
+			client:    newHTTPClient(config),
+			retry:     config.Retry,
+		},
+		token: config.Token,
+	}
+}
+
+// WithToken 返回使用指定 token 的客户端副本，token 为空时沿用 api-sources 中配置的 token
+func (g *GiteeClient) WithToken(token string) *GiteeClient {
+	if token == "" {
+		return g
+	}
+	clone := *g
+	clone.token = token
+	return &clone
+}
+
+// GetRepository 获取仓库信息，repo 格式为 owner/repo
+func (g *GiteeClient) GetRepository(ctx context.Context, repo string) (*GiteeRepo, error) {
+	var repository GiteeRepo
+	if _, err := g.get(ctx, fmt.Sprintf("/repos/%s", repo), nil, &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// GetLatestRelease 获取最新发行版
+func (g *GiteeClient) GetLatestRelease(ctx context.Context, repo string) (*GiteeRelease, error) {
+	var release GiteeRelease
+	if _, err := g.get(ctx, fmt.Sprintf("/repos/%s/releases/latest", repo), nil, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// ListReleases 获取发行版列表
+func (g *GiteeClient) ListReleases(ctx context.Context, repo string, opts GiteeListOptions) ([]GiteeRelease, error) {
+	var releases []GiteeRelease
+	err := g.list(ctx, fmt.Sprintf("/repos/%s/releases", repo), nil, opts, func(body []byte) error {
+		var page []GiteeRelease
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		releases = append(releases, page...)
+		return nil
+	})
+	return releases, err
+}
+
+// ListIssues 获取 Issue 列表，默认只返回打开的 Issue
+func (g *GiteeClient) ListIssues(ctx context.Context, repo string, opts GiteeListOptions) ([]GiteeIssue, error) {
+	var issues []GiteeIssue
+	err := g.list(ctx, fmt.Sprintf("/repos/%s/issues", repo), stateParams(opts), opts, func(body []byte) error {
+		var page []GiteeIssue
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		issues = append(issues, page...)
+		return nil
+	})
+	return issues, err
+}
+
+// ListPullRequests 获取 Pull Request 列表，默认只返回打开的 Pull Request
+func (g *GiteeClient) ListPullRequests(ctx context.Context, repo string, opts GiteeListOptions) ([]GiteePullRequest, error) {
+	var pulls []GiteePullRequest
+	err := g.list(ctx, fmt.Sprintf("/repos/%s/pulls", repo), stateParams(opts), opts, func(body []byte) error {
+		var page []GiteePullRequest
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		pulls = append(pulls, page...)
+		return nil
+	})
+	return pulls, err
+}
+
+// ListCommits 获取默认分支的提交列表
+func (g *GiteeClient) ListCommits(ctx context.Context, repo string, opts GiteeListOptions) ([]GiteeCommit, error) {
+	var commits []GiteeCommit
+	err := g.list(ctx, fmt.Sprintf("/repos/%s/commits", repo), nil, opts, func(body []byte) error {
+		var page []GiteeCommit
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		commits = append(commits, page...)
+		return nil
+	})
+	return commits, err
+}
+
+func stateParams(opts GiteeListOptions) map[string]interface{} {
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	return map[string]interface{}{"state": state}
+}
+
+// list 按 Link 响应头中的 rel="next" 逐页读取列表，每页交给 addPage 解析
+func (g *GiteeClient) list(ctx context.Context, path string, params map[string]interface{}, opts GiteeListOptions, addPage func(body []byte) error) error {
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = 20
+	}
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = 1
+	}
+
+	pageParams := map[string]interface{}{"page": 1, "per_page": perPage}
+	for key, value := range params {
+		pageParams[key] = value
+	}
+
+	for page := 0; page < maxPages; page++ {
+		resp, err := g.get(ctx, path, pageParams, nil)
+		if err != nil {
+			return err
+		}
+		if err := addPage(resp.Body); err != nil {
+			return err
+		}
+
+		next, ok := nextPageQuery(resp.Headers["Link"])
+		if !ok {
+			break
+		}
+		for key := range next {
+			pageParams[key] = next.Get(key)
+		}
+	}
+
+	return nil
+}
+
+// get 请求 Gitee API，dest 不为空时解析响应体；token 放在 Authorization 头中，不出现在 URL 里
+func (g *GiteeClient) get(ctx context.Context, path string, params map[string]interface{}, dest interface{}) (*APIResponse, error) {
+	req := &APIRequest{
+		Method: "GET",
+		Path:   path,
+		Params: params,
+		Headers: map[string]string{
+			"User-Agent": "Glance-China/1.0",
+		},
+	}
+	if g.token != "" {
+		req.Headers["Authorization"] = "token " + g.token
+	}
+
+	resp, err := g.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if dest != nil {
+		if err := json.Unmarshal(resp.Body, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// nextPageQuery 解析 Link 响应头，返回 rel="next" 链接的查询参数
+func nextPageQuery(link string) (url.Values, bool) {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		for _, attr := range segments[1:] {
+			if strings.TrimSpace(attr) != `rel="next"` {
+				continue
+			}
+
+			target, err := url.Parse(strings.Trim(strings.TrimSpace(segments[0]), "<>"))
+			if err != nil {
+				return nil, false
+			}
+			return target.Query(), true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyDirect 作为 API 源的 proxy 时直连上游，不使用全局代理和环境变量中的代理
+const proxyDirect = "direct"
+
+// ProxyConfig 全局出站代理，API 源未配置 proxy 时使用
+type ProxyConfig struct {
+	URL     string   `yaml:"url"`      // 代理地址，支持 http、https、socks5、socks5h，可包含用户名和密码
+	NoProxy []string `yaml:"no-proxy"` // 不经过代理的主机：域名（含子域名）、IP、CIDR，可带端口，* 表示全部
+}
+
+// Validate 检查代理配置
+func (c ProxyConfig) Validate() error {
+	if c.URL != "" && c.URL != proxyDirect {
+		if _, err := parseProxyURL(c.URL); err != nil {
+			return err
+		}
+	}
+	_, err := parseNoProxy(c.NoProxy)
+	return err
+}
+
+// withProxyDefaults 为未配置 proxy 的 API 源补充全局代理，并合并 no-proxy 规则
+func withProxyDefaults(source APISourceConfig, global ProxyConfig) APISourceConfig {
+	if source.Proxy == "" {
+		source.Proxy = global.URL
+	}
+	if len(global.NoProxy) > 0 {
+		source.NoProxy = append(append([]string(nil), source.NoProxy...), global.NoProxy...)
+	}
+	return source
+}
+
+// proxyFunc 返回 API 源的代理选择函数，返回 nil 表示直连
+func proxyFunc(config APISourceConfig) (func(*http.Request) (*url.URL, error), error) {
+	var proxy func(*http.Request) (*url.URL, error)
+	switch config.Proxy {
+	case "":
+		proxy = http.ProxyFromEnvironment
+	case proxyDirect:
+		return nil, nil
+	default:
+		proxyURL, err := parseProxyURL(config.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	rules, err := parseNoProxy(config.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return proxy, nil
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if rules.match(req.URL) {
+			return nil, nil
+		}
+		return proxy(req)
+	}, nil
+}
+
+// parseProxyURL 解析代理地址，支持 http、https、socks5 和 socks5h 代理
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return proxyURL, nil
+}
+
+// noProxyRule 一条 no-proxy 规则，port 为空时匹配所有端口
+type noProxyRule struct {
+	domain string
+	ipNet  *net.IPNet
+	port   string
+	all    bool
+}
+
+type noProxyRules []noProxyRule
+
+func parseNoProxy(entries []string) (noProxyRules, error) {
+	var rules noProxyRules
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			rules = append(rules, noProxyRule{all: true})
+			continue
+		}
+
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			rules = append(rules, noProxyRule{ipNet: ipNet})
+			continue
+		}
+
+		var rule noProxyRule
+		host := entry
+		if h, port, err := net.SplitHostPort(entry); err == nil {
+			host, rule.port = h, port
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			rule.ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		} else {
+			rule.domain = strings.TrimPrefix(strings.TrimPrefix(host, "*"), ".")
+			if rule.domain == "" || strings.ContainsAny(rule.domain, "/*") {
+				return nil, fmt.Errorf("invalid no-proxy entry: %s", entry)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// match 判断请求地址是否不经过代理
+func (rules noProxyRules) match(target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+
+	for _, rule := range rules {
+		if rule.all {
+			return true
+		}
+		if rule.port != "" && rule.port != port {
+			continue
+		}
+		if rule.ipNet != nil {
+			if ip != nil && rule.ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if host == rule.domain || strings.HasSuffix(host, "."+rule.domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,44 +1,139 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"sync"
 	"time"
 )
 
+// ErrRateLimitExceeded 超出限流配额，且无法在上下文截止前等到令牌
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
 // RateLimiter 限流器接口
 type RateLimiter interface {
+	// Allow 有可用令牌时消费一个并返回 true，否则立即返回 false
 	Allow(service string) bool
+	// Wait 阻塞直到取得令牌；ctx 截止前无法取得时立即返回 ErrRateLimitExceeded
+	Wait(ctx context.Context, service string) error
+	// Reserve 预占一个令牌，返回使用该令牌前需要等待的时长
+	Reserve(service string) time.Duration
+	// Stats 返回服务当前的令牌情况
+	Stats(service string) RateLimitStats
+	// Scale 将服务的补充速率和突发容量调整为配置值的 factor 倍，factor 为 1 时恢复配置值
+	Scale(service string, factor float64)
 	Reset(service string)
 }
 
+// RateLimitStats 服务的限流状态
+type RateLimitStats struct {
+	Remaining  int       `json:"remaining"`             // 当前可立即使用的令牌数
+	Capacity   int       `json:"capacity"`              // 突发容量
+	Reserved   int       `json:"reserved"`              // 已预占但尚未补充的令牌数
+	NextRefill time.Time `json:"next_refill,omitempty"` // 下一个令牌补充的时间，令牌已满时为零值
+}
+
+type rateLimitWaitKey struct{}
+
+// WithRateLimitWait 标记后台请求：超出限流配额时排队等待令牌，直到 ctx 截止，而不是立即失败
+func WithRateLimitWait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitWaitKey{}, true)
+}
+
+func waitsForRateLimit(ctx context.Context) bool {
+	wait, _ := ctx.Value(rateLimitWaitKey{}).(bool)
+	return wait
+}
+
+// rateLimitError 返回超出配额的错误
+func rateLimitError(service string) error {
+	return fmt.Errorf("%w for service: %s", ErrRateLimitExceeded, service)
+}
+
 // RateLimitConfig 限流配置
 type RateLimitConfig struct {
-	DefaultLimit int           `yaml:"default-limit"` // 默认每分钟请求数
+	DefaultLimit int            `yaml:"default-limit"` // 默认每个时间窗口的请求数
 	Services     map[string]int `yaml:"services"`      // 各服务的限流配置
-	Window       time.Duration `yaml:"window"`        // 时间窗口
+	Window       time.Duration  `yaml:"window"`        // 时间窗口，默认 1 分钟
+	Burst        int            `yaml:"burst"`         // 默认突发容量，即空闲后可连续发出的请求数，默认等于限额
+	Bursts       map[string]int `yaml:"bursts"`        // 各服务的突发容量
+	MaxWait      time.Duration  `yaml:"max-wait"`      // 页面请求超出配额时最多等待的时长，默认不等待；后台刷新始终排队等待
+	Type         string         `yaml:"type"`          // memory（默认）或 redis，redis 在多个副本之间共享配额
+	RedisURL     string         `yaml:"redis-url"`
+	RedisPrefix  string         `yaml:"redis-prefix"`  // 默认 glance:ratelimit:
+	Adaptive     AdaptiveConfig `yaml:"adaptive"`      // 上游返回限流或风控响应时自动降速
 }
 
-// TokenBucketLimiter 令牌桶限流器
+// Limit 返回服务在每个时间窗口内的请求数和时间窗口，未配置时使用默认值
+func (c RateLimitConfig) Limit(service string) (int, time.Duration) {
+	window, limit := c.Window, c.DefaultLimit
+	if window <= 0 {
+		window = time.Minute
+	}
+	if serviceLimit, ok := c.Services[service]; ok {
+		limit = serviceLimit
+	}
+	if limit <= 0 {
+		limit = 60
+	}
+	return limit, window
+}
+
+// burst 返回服务的突发容量，未配置时等于限额
+func (c RateLimitConfig) burst(service string, limit int) int {
+	if burst, ok := c.Bursts[service]; ok && burst > 0 {
+		return burst
+	}
+	if c.Burst > 0 {
+		return c.Burst
+	}
+	return limit
+}
+
+// MergeRateLimits 将 api-sources 中各源的 rate-limit 合并到限流配置，rate-limit.services 中已配置的服务优先
+func MergeRateLimits(rateLimit RateLimitConfig, sources map[string]APISourceConfig) RateLimitConfig {
+	services := make(map[string]int, len(rateLimit.Services)+len(sources))
+	for name, source := range sources {
+		if source.RateLimit > 0 {
+			services[name] = source.RateLimit
+		}
+	}
+	for name, limit := range rateLimit.Services {
+		services[name] = limit
+	}
+
+	rateLimit.Services = services
+	return rateLimit
+}
+
+// TokenBucketLimiter 令牌桶限流器，令牌按 限额/时间窗口 的速率连续补充
 type TokenBucketLimiter struct {
 	buckets map[string]*tokenBucket
+	factors map[string]float64 // 被 Scale 调整过配额的服务
 	config  RateLimitConfig
 	mu      sync.RWMutex
 }
 
 type tokenBucket struct {
-	tokens    int
-	capacity  int
-	refillRate int
+	tokens     float64
+	capacity   float64
+	rate       float64 // 每秒补充的令牌数
 	lastRefill time.Time
-	mu        sync.Mutex
+	mu         sync.Mutex
 }
 
+// NewRateLimiter 按 RateLimitConfig.Type 创建限流器，Redis 配置无效时使用本地限流
 func NewRateLimiter(config RateLimitConfig) RateLimiter {
-	if config.Window == 0 {
-		config.Window = time.Minute
-	}
-	if config.DefaultLimit == 0 {
-		config.DefaultLimit = 60
+	if config.Type == "redis" {
+		limiter, err := NewRedisRateLimiter(config)
+		if err != nil {
+			log.Printf("failed to create redis rate limiter, falling back to memory: %v", err)
+		} else {
+			return limiter
+		}
 	}
 	
 	return &TokenBucketLimiter{
@@ -48,31 +143,109 @@ func NewRateLimiter(config RateLimitConfig) RateLimiter {
 }
 
 func (t *TokenBucketLimiter) Allow(service string) bool {
+	_, ok := t.bucket(service).reserve(time.Now(), 0)
+	return ok
+}
+
+func (t *TokenBucketLimiter) Wait(ctx context.Context, service string) error {
+	maxWait := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		// 截止时间已过时不再预占令牌，以免被当作不限等待时长
+		if maxWait = time.Until(deadline); maxWait <= 0 {
+			return rateLimitError(service)
+		}
+	}
+	
+	bucket := t.bucket(service)
+	delay, ok := bucket.reserve(time.Now(), maxWait)
+	if !ok {
+		return rateLimitError(service)
+	}
+	if delay <= 0 {
+		return nil
+	}
+	
+	if err := sleepContext(ctx, delay); err != nil {
+		bucket.cancel()
+		return err
+	}
+	return nil
+}
+
+func (t *TokenBucketLimiter) Reserve(service string) time.Duration {
+	delay, _ := t.bucket(service).reserve(time.Now(), -1)
+	return delay
+}
+
+func (t *TokenBucketLimiter) Stats(service string) RateLimitStats {
+	return t.bucket(service).stats(time.Now())
+}
+
+// bucket 获取服务的令牌桶，不存在时按配置创建，初始为满
+func (t *TokenBucketLimiter) bucket(service string) *tokenBucket {
 	t.mu.RLock()
 	bucket, exists := t.buckets[service]
 	t.mu.RUnlock()
+	if exists {
+		return bucket
+	}
 	
-	if !exists {
-		t.mu.Lock()
-		// 双重检查
-		if bucket, exists = t.buckets[service]; !exists {
-			limit := t.config.DefaultLimit
-			if serviceLimit, ok := t.config.Services[service]; ok {
-				limit = serviceLimit
-			}
-			
-			bucket = &tokenBucket{
-				tokens:     limit,
-				capacity:   limit,
-				refillRate: limit,
-				lastRefill: time.Now(),
-			}
-			t.buckets[service] = bucket
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	// 双重检查
+	if bucket, exists = t.buckets[service]; !exists {
+		factor, scaled := t.factors[service]
+		if !scaled {
+			factor = 1
+		}
+		rate, capacity := t.params(service, factor)
+		
+		bucket = &tokenBucket{
+			tokens:     capacity,
+			capacity:   capacity,
+			rate:       rate,
+			lastRefill: time.Now(),
+		}
+		t.buckets[service] = bucket
+	}
+	return bucket
+}
+
+// params 返回服务按 factor 缩放后的补充速率和突发容量，容量至少为 1
+func (t *TokenBucketLimiter) params(service string, factor float64) (rate, capacity float64) {
+	limit, window := t.config.Limit(service)
+	capacity = math.Max(1, math.Floor(float64(t.config.burst(service, limit))*factor))
+	return float64(limit) / window.Seconds() * factor, capacity
+}
+
+func (t *TokenBucketLimiter) Scale(service string, factor float64) {
+	t.mu.Lock()
+	if factor >= 1 {
+		factor = 1
+		delete(t.factors, service)
+	} else {
+		if t.factors == nil {
+			t.factors = make(map[string]float64)
 		}
-		t.mu.Unlock()
+		t.factors[service] = factor
+	}
+	bucket, exists := t.buckets[service]
+	t.mu.Unlock()
+	
+	if !exists {
+		return
 	}
 	
-	return bucket.consume()
+	rate, capacity := t.params(service, factor)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	
+	bucket.refill(time.Now())
+	bucket.rate, bucket.capacity = rate, capacity
+	if bucket.tokens > capacity {
+		bucket.tokens = capacity
+	}
 }
 
 func (t *TokenBucketLimiter) Reset(service string) {
@@ -87,32 +260,61 @@ func (t *TokenBucketLimiter) Reset(service string) {
 	}
 }
 
-func (b *tokenBucket) consume() bool {
+// refill 按距上次补充的时间补充令牌，不超过容量
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.lastRefill = now
+	}
+}
+
+// reserve 预占一个令牌，令牌数可以为负，表示已预占的令牌；
+// 需要等待的时长超过 maxWait 时不预占并返回 false，maxWait 为负表示不限
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	
-	// 补充令牌
-	now := time.Now()
-	elapsed := now.Sub(b.lastRefill)
-	tokensToAdd := int(elapsed.Minutes()) * b.refillRate
+	b.refill(now)
 	
-	if tokensToAdd > 0 {
-		b.tokens = min(b.capacity, b.tokens+tokensToAdd)
-		b.lastRefill = now
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
 	}
-	
-	// 消费令牌
-	if b.tokens > 0 {
-		b.tokens--
-		return true
+	if maxWait >= 0 && delay > maxWait {
+		return delay, false
 	}
 	
-	return false
+	b.tokens--
+	return delay, true
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// cancel 归还未使用的预占令牌
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	b.tokens = math.Min(b.tokens+1, b.capacity)
+}
+
+func (b *tokenBucket) stats(now time.Time) RateLimitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	b.refill(now)
+	
+	stats := RateLimitStats{Capacity: int(b.capacity)}
+	if b.tokens >= 0 {
+		stats.Remaining = int(b.tokens)
+	} else {
+		stats.Reserved = int(math.Ceil(-b.tokens))
+	}
+	if b.tokens < b.capacity {
+		// 距离下一个整数令牌的时间
+		next := math.Floor(b.tokens) + 1 - b.tokens
+		stats.NextRefill = now.Add(time.Duration(next / b.rate * float64(time.Second)))
 	}
-	return b
+	return stats
 }
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRateLimitRedisPrefix = "glance:ratelimit:"
+	// redisLimiterTimeout 单次 Redis 操作的超时时间，超时后使用本地限流
+	redisLimiterTimeout = 200 * time.Millisecond
+)
+
+// 令牌桶以 GCRA 实现：键中保存理论到达时间（TAT，微秒），发放间隔 interval = 时间窗口/限额，
+// 容量 burst 个令牌对应 burst*interval 的容忍时长。时间取自 Redis，各副本之间不受本地时钟偏差影响。
+
+// reserveScript 预占一个令牌；ARGV: 发放间隔、容忍时长、最多等待时长（微秒，负数不限）。
+// 返回 {是否预占, 需要等待的微秒数}
+var reserveScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local max_wait = tonumber(ARGV[3])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or 0)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local delay = new_tat - tolerance - now
+if delay < 0 then
+	delay = 0
+end
+if max_wait >= 0 and delay > max_wait then
+	return {0, delay}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, delay}
+`)
+
+// cancelScript 归还一个预占的令牌；ARGV: 发放间隔（微秒）
+var cancelScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or 0) - tonumber(ARGV[1])
+
+if tat > now then
+	redis.call('SET', KEYS[1], string.format('%d', tat), 'PX', math.ceil((tat - now) / 1000))
+else
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+// statsScript 返回 {Redis 当前时间, TAT}，单位微秒
+var statsScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+return {now, tonumber(redis.call('GET', KEYS[1]) or 0)}
+`)
+
+// RedisRateLimiter 通过 Redis 在多个副本之间共享令牌桶；Redis 不可用时使用本地令牌桶
+type RedisRateLimiter struct {
+	client   redis.UniversalClient
+	prefix   string
+	config   RateLimitConfig
+	local    *TokenBucketLimiter
+	factors  map[string]float64 // 本副本被 Scale 调整过配额的服务
+	mu       sync.RWMutex
+	degraded int32
+}
+
+// NewRedisRateLimiter 根据 RateLimitConfig.RedisURL 创建分布式限流器
+func NewRedisRateLimiter(config RateLimitConfig) (*RedisRateLimiter, error) {
+	redisURL := config.RedisURL
+	if redisURL == "" {
+		redisURL = defaultRedisURL
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	return NewRedisRateLimiterWithClient(redis.NewClient(options), config), nil
+}
+
+// NewRedisRateLimiterWithClient 使用已有的 Redis 客户端创建分布式限流器
+func NewRedisRateLimiterWithClient(client redis.UniversalClient, config RateLimitConfig) *RedisRateLimiter {
+	prefix := config.RedisPrefix
+	if prefix == "" {
+		prefix = defaultRateLimitRedisPrefix
+	}
+
+	return &RedisRateLimiter{
+		client: client,
+		prefix: prefix,
+		config: config,
+		local: &TokenBucketLimiter{
+			buckets: make(map[string]*tokenBucket),
+			config:  config,
+		},
+	}
+}
+
+func (r *RedisRateLimiter) Allow(service string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	ok, _, err := r.reserve(ctx, service, 0)
+	if err != nil {
+		return r.local.Allow(service)
+	}
+	return ok
+}
+
+func (r *RedisRateLimiter) Wait(ctx context.Context, service string) error {
+	maxWait := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		// 截止时间已过时不再预占令牌，以免被当作不限等待时长
+		if maxWait = time.Until(deadline); maxWait <= 0 {
+			return rateLimitError(service)
+		}
+	}
+
+	redisCtx, cancel := context.WithTimeout(ctx, redisLimiterTimeout)
+	ok, delay, err := r.reserve(redisCtx, service, maxWait)
+	cancel()
+	if err != nil {
+		return r.local.Wait(ctx, service)
+	}
+	if !ok {
+		return rateLimitError(service)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	if err := sleepContext(ctx, delay); err != nil {
+		r.cancel(service)
+		return err
+	}
+	return nil
+}
+
+func (r *RedisRateLimiter) Reserve(service string) time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	_, delay, err := r.reserve(ctx, service, -1)
+	if err != nil {
+		return r.local.Reserve(service)
+	}
+	return delay
+}
+
+func (r *RedisRateLimiter) Stats(service string) RateLimitStats {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	result, err := statsScript.Run(ctx, r.client, []string{r.prefix + service}).Int64Slice()
+	if err != nil {
+		r.fallback(err)
+		return r.local.Stats(service)
+	}
+	r.recover()
+
+	interval, tolerance := r.params(service)
+	now, tat := result[0], result[1]
+
+	stats := RateLimitStats{Capacity: int(math.Round(float64(tolerance) / float64(interval)))}
+	if tat <= now {
+		stats.Remaining = stats.Capacity
+		return stats
+	}
+
+	// 与本地令牌桶相同的换算：可用令牌 = (now + 容忍时长 - TAT) / 发放间隔，可以为负
+	tokens := float64(now+tolerance-tat) / float64(interval)
+	if tokens >= 0 {
+		stats.Remaining = int(tokens)
+	} else {
+		stats.Reserved = int(math.Ceil(-tokens))
+	}
+	next := (math.Floor(tokens) + 1 - tokens) * float64(interval)
+	stats.NextRefill = time.UnixMicro(now + int64(next))
+	return stats
+}
+
+func (r *RedisRateLimiter) Reset(service string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	if err := r.client.Del(ctx, r.prefix+service).Err(); err != nil {
+		r.fallback(err)
+	}
+	r.local.Reset(service)
+}
+
+// Scale 只调整本副本的发放间隔和容忍时长，其他副本仍按配置值共享同一个令牌桶
+func (r *RedisRateLimiter) Scale(service string, factor float64) {
+	r.mu.Lock()
+	if factor >= 1 {
+		delete(r.factors, service)
+	} else {
+		if r.factors == nil {
+			r.factors = make(map[string]float64)
+		}
+		r.factors[service] = factor
+	}
+	r.mu.Unlock()
+
+	r.local.Scale(service, factor)
+}
+
+// Close 关闭 Redis 连接
+func (r *RedisRateLimiter) Close() error {
+	return r.client.Close()
+}
+
+// params 返回服务的发放间隔和容忍时长，单位微秒
+func (r *RedisRateLimiter) params(service string) (interval, tolerance int64) {
+	r.mu.RLock()
+	factor, scaled := r.factors[service]
+	r.mu.RUnlock()
+	if !scaled {
+		factor = 1
+	}
+
+	limit, window := r.config.Limit(service)
+	interval = int64(float64(window.Microseconds()) / float64(limit) / factor)
+	if interval < 1 {
+		interval = 1
+	}
+	burst := math.Max(1, math.Floor(float64(r.config.burst(service, limit))*factor))
+	return interval, interval * int64(burst)
+}
+
+// reserve 在 Redis 中预占一个令牌，maxWait 为负表示不限等待时长
+func (r *RedisRateLimiter) reserve(ctx context.Context, service string, maxWait time.Duration) (bool, time.Duration, error) {
+	interval, tolerance := r.params(service)
+	wait := int64(-1)
+	if maxWait >= 0 {
+		wait = maxWait.Microseconds()
+	}
+
+	result, err := reserveScript.Run(ctx, r.client, []string{r.prefix + service}, interval, tolerance, wait).Int64Slice()
+	if err != nil {
+		r.fallback(err)
+		return false, 0, err
+	}
+	r.recover()
+
+	return result[0] == 1, time.Duration(result[1]) * time.Microsecond, nil
+}
+
+// cancel 归还 Wait 期间被取消的预占令牌
+func (r *RedisRateLimiter) cancel(service string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	interval, _ := r.params(service)
+	if err := cancelScript.Run(ctx, r.client, []string{r.prefix + service}, interval).Err(); err != nil {
+		r.fallback(err)
+	}
+}
+
+// fallback 记录 Redis 不可用，只在状态变化时输出日志
+func (r *RedisRateLimiter) fallback(err error) {
+	if atomic.CompareAndSwapInt32(&r.degraded, 0, 1) {
+		log.Printf("redis rate limiter unavailable, falling back to local limiting: %v", err)
+	}
+}
+
+func (r *RedisRateLimiter) recover() {
+	if atomic.CompareAndSwapInt32(&r.degraded, 1, 0) {
+		log.Printf("redis rate limiter recovered")
+	}
+}
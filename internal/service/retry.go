@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryConfig API 源的重试策略，max-attempts 不大于 1 时不重试
+type RetryConfig struct {
+	MaxAttempts     int           `yaml:"max-attempts"`     // 最多尝试次数（含首次请求）
+	BaseDelay       time.Duration `yaml:"base-delay"`       // 首次重试前的等待时间，之后按指数增长，默认 200ms
+	MaxDelay        time.Duration `yaml:"max-delay"`        // 单次等待上限，上游要求的 Retry-After 超过该值时不再重试，默认 5s
+	RetryableStatus []int         `yaml:"retryable-status"` // 需要重试的状态码，默认 408、500、502、503、504
+	NonIdempotent   bool          `yaml:"non-idempotent"`   // 是否也重试 POST、PATCH 等非幂等请求
+}
+
+var defaultRetryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// attempts 返回该方法的请求最多尝试的次数
+func (r RetryConfig) attempts(method string) int {
+	if r.MaxAttempts <= 1 {
+		return 1
+	}
+	if !r.NonIdempotent && !isIdempotent(method) {
+		return 1
+	}
+	return r.MaxAttempts
+}
+
+// shouldRetry 判断一次失败的尝试是否值得重试
+func (r RetryConfig) shouldRetry(ctx context.Context, resp *APIResponse, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	// 调用方的 ctx 仍然有效时，网络错误和单次请求超时都可以重试
+	if err != nil {
+		return true
+	}
+
+	statuses := r.RetryableStatus
+	if len(statuses) == 0 {
+		statuses = defaultRetryableStatus
+	}
+	for _, status := range statuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delay 计算第 attempt 次尝试失败后的等待时间：指数退避加抖动，上游返回 Retry-After 时至少等待该时长；
+// Retry-After 超过 max-delay 时返回 false，不再重试
+func (r RetryConfig) delay(attempt int, resp *APIResponse) (time.Duration, bool) {
+	base := r.BaseDelay
+	if base <= 0 {
+		base = 200 * time.Millisecond
+	}
+	max := r.MaxDelay
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+
+	backoff := base
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	// 等待时间在 [backoff/2, backoff) 之间随机，避免多个实例同时重试
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Headers["Retry-After"]); ok && retryAfter > delay {
+			if retryAfter > max {
+				return 0, false
+			}
+			delay = retryAfter
+		}
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay, true
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleepContext 等待指定时长，ctx 结束时提前返回其错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
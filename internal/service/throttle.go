@@ -0,0 +1,219 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUpstreamThrottled 上游返回了限流或风控响应，该源处于退避期
+var ErrUpstreamThrottled = errors.New("upstream throttled")
+
+// ThrottleError 上游限流或风控响应，errors.Is(err, ErrUpstreamThrottled) 为 true
+type ThrottleError struct {
+	Service    string
+	StatusCode int       // 触发限流的响应状态码，退避期内直接拒绝时为 0
+	Until      time.Time // 退避结束的时间
+}
+
+func (e *ThrottleError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s upstream throttled (status %d), backing off until %s", e.Service, e.StatusCode, e.Until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s upstream throttled, backing off until %s", e.Service, e.Until.Format(time.RFC3339))
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return ErrUpstreamThrottled
+}
+
+// AdaptiveConfig 收到上游限流信号后自动降速的配置
+type AdaptiveConfig struct {
+	Disabled         bool          `yaml:"disabled"`
+	Backoff          time.Duration `yaml:"backoff"`           // 收到限流信号后暂停请求的时长，连续触发时加倍，默认 30s
+	MaxBackoff       time.Duration `yaml:"max-backoff"`       // 暂停时长上限，默认 10m
+	MinFactor        float64       `yaml:"min-factor"`        // 配额最多缩小到的比例，默认 0.1
+	RecoveryInterval time.Duration `yaml:"recovery-interval"` // 暂停结束后每隔多久将配额加倍，直到恢复原配额，默认 1m
+}
+
+// ThrottleStatus API 源的自适应限流状态，用于状态页展示
+type ThrottleStatus struct {
+	Throttled bool      `json:"throttled"`       // 是否处于退避期
+	Until     time.Time `json:"until,omitempty"` // 退避结束的时间
+	Factor    float64   `json:"factor"`          // 当前配额相对配置值的比例
+	Hits      int       `json:"hits"`            // 恢复原配额前累计收到的限流信号次数
+}
+
+// throttleClassifier 识别各上游特有的限流和风控响应
+type throttleClassifier interface {
+	throttled(resp *APIResponse) bool
+}
+
+// throttled 默认只把 429 视为限流信号
+func (b *BaseClient) throttled(resp *APIResponse) bool {
+	return resp.StatusCode == http.StatusTooManyRequests
+}
+
+// throttled Bilibili 以 HTTP 412 或响应体中的 code -412（风控）、-509、-799（请求过于频繁）拒绝请求
+func (b *BilibiliClient) throttled(resp *APIResponse) bool {
+	switch resp.StatusCode {
+	case http.StatusPreconditionFailed, http.StatusTooManyRequests:
+		return true
+	}
+
+	var body struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal(resp.Body, &body) != nil {
+		return false
+	}
+	switch body.Code {
+	case -412, -509, -799:
+		return true
+	}
+	return false
+}
+
+// throttled 微博以 418 或 429 拒绝请求
+func (w *WeiboClient) throttled(resp *APIResponse) bool {
+	return resp.StatusCode == http.StatusTeapot || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// throttleState 单个 API 源的退避状态
+type throttleState struct {
+	factor  float64
+	until   time.Time
+	backoff time.Duration
+	hits    int
+	changed time.Time // 最近一次调整配额的时间
+}
+
+// adaptiveThrottle 按上游限流信号调整各 API 源的配额：收到信号时配额减半并暂停请求，之后逐步恢复
+type adaptiveThrottle struct {
+	config AdaptiveConfig
+	states map[string]*throttleState
+	mu     sync.Mutex
+}
+
+func newAdaptiveThrottle(config AdaptiveConfig) *adaptiveThrottle {
+	if config.Backoff <= 0 {
+		config.Backoff = 30 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 10 * time.Minute
+	}
+	if config.MinFactor <= 0 || config.MinFactor > 1 {
+		config.MinFactor = 0.1
+	}
+	if config.RecoveryInterval <= 0 {
+		config.RecoveryInterval = time.Minute
+	}
+
+	return &adaptiveThrottle{config: config, states: make(map[string]*throttleState)}
+}
+
+// check 在发送请求前调用：退避期内返回 *ThrottleError；退避结束后按恢复间隔逐步放大配额，
+// 返回当前配额比例以及比例是否发生变化
+func (a *adaptiveThrottle) check(service string, now time.Time) (float64, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, exists := a.states[service]
+	if !exists {
+		return 1, false, nil
+	}
+	if now.Before(state.until) {
+		return state.factor, false, &ThrottleError{Service: service, Until: state.until}
+	}
+
+	if now.Sub(laterTime(state.until, state.changed)) < a.config.RecoveryInterval {
+		return state.factor, false, nil
+	}
+
+	state.factor *= 2
+	state.changed = now
+	if state.factor >= 1 {
+		delete(a.states, service)
+		log.Printf("upstream %s recovered from throttling", service)
+		return 1, true, nil
+	}
+	return state.factor, true, nil
+}
+
+// throttle 记录一次限流信号：配额减半，暂停请求，连续触发时暂停时长加倍；retryAfter 为上游要求的等待时长
+func (a *adaptiveThrottle) throttle(service string, statusCode int, retryAfter time.Duration, now time.Time) (*ThrottleError, float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, exists := a.states[service]
+	if !exists {
+		state = &throttleState{factor: 1}
+		a.states[service] = state
+	}
+
+	// 上一次退避结束后不久再次触发时视为连续触发
+	if exists && now.Before(state.until.Add(a.config.RecoveryInterval)) {
+		state.backoff *= 2
+	} else {
+		state.backoff = a.config.Backoff
+	}
+	if state.backoff > a.config.MaxBackoff {
+		state.backoff = a.config.MaxBackoff
+	}
+
+	wait := state.backoff
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	state.until = now.Add(wait)
+	state.factor /= 2
+	if state.factor < a.config.MinFactor {
+		state.factor = a.config.MinFactor
+	}
+	state.hits++
+	state.changed = now
+
+	log.Printf("upstream %s throttled (status %d), backing off for %v at %.0f%% of the configured rate", service, statusCode, wait, state.factor*100)
+
+	return &ThrottleError{Service: service, StatusCode: statusCode, Until: state.until}, state.factor
+}
+
+// factors 返回配额被缩小的各服务及其比例
+func (a *adaptiveThrottle) factors() map[string]float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	factors := make(map[string]float64, len(a.states))
+	for service, state := range a.states {
+		factors[service] = state.factor
+	}
+	return factors
+}
+
+// status 返回服务的退避状态
+func (a *adaptiveThrottle) status(service string, now time.Time) ThrottleStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, exists := a.states[service]
+	if !exists {
+		return ThrottleStatus{Factor: 1}
+	}
+	return ThrottleStatus{
+		Throttled: now.Before(state.until),
+		Until:     state.until,
+		Factor:    state.factor,
+		Hits:      state.hits,
+	}
+}
+
+func laterTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// TransportConfig API 源的连接配置，配置相同的源共用同一个连接池
+type TransportConfig struct {
+	MaxIdleConns        int           `yaml:"max-idle-conns"`          // 所有主机的空闲连接上限，默认 100
+	MaxIdleConnsPerHost int           `yaml:"max-idle-conns-per-host"` // 每个主机的空闲连接上限，默认 10
+	MaxConnsPerHost     int           `yaml:"max-conns-per-host"`      // 每个主机的连接上限，默认不限制
+	IdleConnTimeout     time.Duration `yaml:"idle-conn-timeout"`       // 空闲连接保留时长，默认 90s
+	DialTimeout         time.Duration `yaml:"dial-timeout"`            // 建立连接的超时时间，默认 10s
+	TLSHandshakeTimeout time.Duration `yaml:"tls-handshake-timeout"`   // TLS 握手超时时间，默认 10s
+	DisableKeepAlives   bool          `yaml:"disable-keep-alives"`
+	DisableHTTP2        bool          `yaml:"disable-http2"`
+	TLS                 TLSConfig     `yaml:"tls"`
+}
+
+// TLSConfig HTTPS 连接配置
+type TLSConfig struct {
+	MinVersion         string `yaml:"min-version"` // 1.0、1.1、1.2、1.3，默认 1.2
+	ServerName         string `yaml:"server-name"`
+	CAFile             string `yaml:"ca-file"` // 额外信任的 CA 证书（PEM 格式）
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newHTTPClient 创建 API 源使用的 HTTP 客户端；超时统一通过请求的 context 控制，
+// 客户端不设置 Timeout，以免覆盖更长的单个请求超时或调用方的截止时间。
+// 由服务管理器创建的客户端随后改用管理器连接池中的 Transport
+func newHTTPClient(config APISourceConfig) *http.Client {
+	return &http.Client{
+		Transport: transportFor(config),
+		Jar:       newCookieJar(config),
+	}
+}
+
+// newCookieJar 创建携带 api-sources 中配置的 Cookie 的 Cookie Jar，未配置 Cookie 时返回 nil
+func newCookieJar(config APISourceConfig) http.CookieJar {
+	if len(config.Cookies) == 0 {
+		return nil
+	}
+
+	jar, _ := cookiejar.New(nil)
+	target, err := url.Parse(config.BaseURL)
+	if err != nil {
+		log.Printf("invalid base-url %q, ignoring configured cookies: %v", config.BaseURL, err)
+		return jar
+	}
+
+	cookies := make([]*http.Cookie, 0, len(config.Cookies))
+	for name, value := range config.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: name, Value: value, Path: "/"})
+	}
+	jar.SetCookies(target, cookies)
+	return jar
+}
+
+// transportKey 连接配置相同的源得到相同的 key
+func transportKey(config APISourceConfig) string {
+	return fmt.Sprintf("%+v|%s|%v", config.Transport, config.Proxy, config.NoProxy)
+}
+
+// transportFor 按配置创建 Transport，配置无效时记录日志并使用默认设置
+func transportFor(config APISourceConfig) *http.Transport {
+	transport, err := newTransport(config)
+	if err != nil {
+		log.Printf("invalid transport config, using defaults: %v", err)
+		transport, _ = newTransport(APISourceConfig{})
+	}
+	return transport
+}
+
+// transportPool 服务管理器按连接配置共享的 Transport，配置热更新后未变化的源继续复用已有连接
+type transportPool struct {
+	transports map[string]*http.Transport
+	mu         sync.Mutex
+}
+
+func newTransportPool() *transportPool {
+	return &transportPool{transports: make(map[string]*http.Transport)}
+}
+
+// get 获取与配置对应的共享 Transport
+func (p *transportPool) get(config APISourceConfig) *http.Transport {
+	key := transportKey(config)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if transport, exists := p.transports[key]; exists {
+		return transport
+	}
+
+	transport := transportFor(config)
+	p.transports[key] = transport
+	return transport
+}
+
+// prune 关闭并移除不再被任何源使用的 Transport，配置热更新后调用
+func (p *transportPool) prune(sources []APISourceConfig) {
+	keep := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		keep[transportKey(source)] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, transport := range p.transports {
+		if !keep[key] {
+			transport.CloseIdleConnections()
+			delete(p.transports, key)
+		}
+	}
+}
+
+// newTransport 按配置创建 Transport
+func newTransport(config APISourceConfig) (*http.Transport, error) {
+	tc := config.Transport
+
+	tlsConfig, err := newTLSConfig(tc.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := proxyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   durationOr(tc.DialTimeout, 10*time.Second),
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !tc.DisableHTTP2,
+		MaxIdleConns:          intOr(tc.MaxIdleConns, 100),
+		MaxIdleConnsPerHost:   intOr(tc.MaxIdleConnsPerHost, 10),
+		MaxConnsPerHost:       tc.MaxConnsPerHost,
+		IdleConnTimeout:       durationOr(tc.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   durationOr(tc.TLSHandshakeTimeout, 10*time.Second),
+		ExpectContinueTimeout: time.Second,
+		DisableKeepAlives:     tc.DisableKeepAlives,
+	}
+	if tc.DisableHTTP2 {
+		// 非空的 TLSNextProto 阻止 Transport 协商 HTTP/2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(config TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version: %s", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file: %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// Validate 检查 API 源的客户端类型和连接配置
+func (c APISourceConfig) Validate() error {
+	if _, ok := clientConstructors[c.Type]; c.Type != "" && !ok {
+		return fmt.Errorf("unknown type: %s", c.Type)
+	}
+	_, err := newTransport(c)
+	return err
+}
+
+func intOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"time"
 )
 
 // WeiboClient 微博客户端
@@ -43,7 +41,8 @@ func NewWeiboClient(config APISourceConfig) *WeiboClient {
 			baseURL:   config.BaseURL,
 			timeout:   config.Timeout,
 			headers:   config.Headers,
-			client:    &http.Client{Timeout: config.Timeout},
+			client:    newHTTPClient(config),
+			retry:     config.Retry,
 		},
 		appKey:    config.Headers["app-key"],
 		appSecret: config.Token,
@@ -58,10 +57,9 @@ func (w *WeiboClient) GetHotSearch(ctx context.Context) ([]WeiboHotSearchData, e
 		Headers: map[string]string{
 			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
 		},
-		Timeout: 10 * time.Second,
 	}
 	
-	resp, err := w.Request(ctx, req)
+	resp, err := w.send(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ZhihuClient 知乎客户端
+type ZhihuClient struct {
+	BaseClient
+}
+
+// ZhihuAuthor 知乎作者
+type ZhihuAuthor struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	AvatarURL string `json:"avatar_url"`
+	Headline  string `json:"headline"`
+}
+
+// ZhihuHotItem 知乎热榜条目
+type ZhihuHotItem struct {
+	Target struct {
+		ID            int64       `json:"id"`
+		Title         string      `json:"title"`
+		Excerpt       string      `json:"excerpt"`
+		URL           string      `json:"url"`
+		AnswerCount   int         `json:"answer_count"`
+		FollowerCount int         `json:"follower_count"`
+		Created       int64       `json:"created"`
+		Author        ZhihuAuthor `json:"author"`
+	} `json:"target"`
+	DetailText string `json:"detail_text"`
+	HeatValue  int64  `json:"heat_value"`
+}
+
+// ZhihuQuestion 知乎问题
+type ZhihuQuestion struct {
+	ID            int64  `json:"id"`
+	Title         string `json:"title"`
+	Detail        string `json:"detail"`
+	URL           string `json:"url"`
+	AnswerCount   int    `json:"answer_count"`
+	FollowerCount int    `json:"follower_count"`
+	VisitCount    int64  `json:"visit_count"`
+	Created       int64  `json:"created"`
+	UpdatedTime   int64  `json:"updated_time"`
+}
+
+// ZhihuArticle 知乎专栏文章
+type ZhihuArticle struct {
+	ID           int64       `json:"id"`
+	Title        string      `json:"title"`
+	Excerpt      string      `json:"excerpt"`
+	URL          string      `json:"url"`
+	ImageURL     string      `json:"image_url"`
+	VoteupCount  int         `json:"voteup_count"`
+	CommentCount int         `json:"comment_count"`
+	Created      int64       `json:"created"`
+	Updated      int64       `json:"updated"`
+	Author       ZhihuAuthor `json:"author"`
+}
+
+func NewZhihuClient(config APISourceConfig) *ZhihuClient {
+	return &ZhihuClient{
+		BaseClient: BaseClient{
+			name:      "zhihu",
+			baseURL:   config.BaseURL,
+			timeout:   config.Timeout,
+			headers:   config.Headers,
+			client:    newHTTPClient(config),
+			retry:     config.Retry,
+		},
+	}
+}
+
+// GetHotList 获取知乎热榜
+func (z *ZhihuClient) GetHotList(ctx context.Context, limit int) ([]ZhihuHotItem, error) {
+	var apiResp struct {
+		Data []ZhihuHotItem `json:"data"`
+	}
+
+	if err := z.get(ctx, "/v3/feed/topstory/hot-lists/total", map[string]interface{}{"limit": limit}, &apiResp); err != nil {
+		return nil, err
+	}
+
+	return apiResp.Data, nil
+}
+
+// GetQuestion 获取问题详情
+func (z *ZhihuClient) GetQuestion(ctx context.Context, questionID string) (*ZhihuQuestion, error) {
+	params := map[string]interface{}{
+		"include": "detail,answer_count,follower_count,visit_count,created,updated_time",
+	}
+
+	var question ZhihuQuestion
+	if err := z.get(ctx, fmt.Sprintf("/v4/questions/%s", questionID), params, &question); err != nil {
+		return nil, err
+	}
+
+	return &question, nil
+}
+
+// GetColumnArticles 获取专栏文章，offset 用于翻页
+func (z *ZhihuClient) GetColumnArticles(ctx context.Context, columnID string, limit, offset int) ([]ZhihuArticle, error) {
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	var apiResp struct {
+		Data []ZhihuArticle `json:"data"`
+	}
+	if err := z.get(ctx, fmt.Sprintf("/v4/columns/%s/items", columnID), params, &apiResp); err != nil {
+		return nil, err
+	}
+
+	return apiResp.Data, nil
+}
+
+func (z *ZhihuClient) get(ctx context.Context, path string, params map[string]interface{}, dest interface{}) error {
+	req := &APIRequest{
+		Method: "GET",
+		Path:   path,
+		Params: params,
+		Headers: map[string]string{
+			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+			"Referer":    "https://www.zhihu.com",
+		},
+	}
+
+	resp, err := z.send(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(resp.Body, dest)
+}
@@ -2,9 +2,11 @@ package widget
 
 import (
 	"context"
+	"errors"
 	"time"
 	
-	"github.com/glance-china/internal/i18n"
+	"github.com/linzi007/glance-china/internal/i18n"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // Widget 定义所有组件的基础接口
@@ -28,6 +30,7 @@ type BaseWidget struct {
 	localizer    *i18n.Localizer
 }
 
+// InitLocalizer 设置组件默认使用的语言，需在组件开始加载数据之前调用
 func (b *BaseWidget) InitLocalizer(locale string) {
 	if locale == "" {
 		locale = "zh-CN" // 默认中文
@@ -36,13 +39,23 @@ func (b *BaseWidget) InitLocalizer(locale string) {
 	b.localizer = i18n.NewLocalizer(locale)
 }
 
+// GetLocalizer 返回组件默认语言的本地化器；未初始化时按配置的语言临时创建，不修改组件
 func (b *BaseWidget) GetLocalizer() *i18n.Localizer {
 	if b.localizer == nil {
-		b.InitLocalizer("zh-CN")
+		return i18n.NewLocalizer(b.Locale)
 	}
 	return b.localizer
 }
 
+// localizerFrom 返回本次加载使用的本地化器，优先使用上下文中的语言；
+// 同一组件实例会被并发加载，当前语言只随上下文传递，不写入组件
+func (b *BaseWidget) localizerFrom(ctx context.Context) *i18n.Localizer {
+	if locale, ok := ctx.Value("locale").(string); ok && locale != "" {
+		return i18n.NewLocalizer(locale)
+	}
+	return b.GetLocalizer()
+}
+
 func (b *BaseWidget) T(key string, args ...interface{}) string {
 	return b.GetLocalizer().T(key, args...)
 }
@@ -58,6 +71,11 @@ func (b *BaseWidget) GetCacheDuration() time.Duration {
 	return b.cacheDuration
 }
 
+// SetCacheDuration 设置组件数据的缓存时长，由配置中的 cache 解析而来
+func (b *BaseWidget) SetCacheDuration(duration time.Duration) {
+	b.cacheDuration = duration
+}
+
 func (b *BaseWidget) Validate(config Config) error {
 	// 基础验证逻辑
 	return nil
@@ -65,12 +83,36 @@ func (b *BaseWidget) Validate(config Config) error {
 
 // ChineseWidget 中国版组件基础结构
 type ChineseWidget struct {
-	BaseWidget
+	BaseWidget `yaml:",inline"`
 	Region    string   `yaml:"region,omitempty"`
 	APISource string   `yaml:"api-source,omitempty"`
 	Fallback  []string `yaml:"fallback,omitempty"`
 }
 
+// GetAPISource 获取组件使用的 API 源
+func (c *ChineseWidget) GetAPISource() string {
+	return c.APISource
+}
+
+// serviceClient 获取组件 api-source 对应的客户端，未配置 api-source 或该源不存在时使用默认源
+func (c *ChineseWidget) serviceClient(serviceManager *service.ServiceManager, defaultSource string) (service.APIClient, error) {
+	if c.APISource != "" && c.APISource != defaultSource {
+		if client, err := serviceManager.GetClient(c.APISource); err == nil {
+			return client, nil
+		}
+	}
+	return serviceManager.GetClient(defaultSource)
+}
+
+// serviceManagerFrom 获取上下文中的服务管理器，组件的上游请求都经由它发送
+func serviceManagerFrom(ctx context.Context) (*service.ServiceManager, error) {
+	serviceManager, ok := ctx.Value("serviceManager").(*service.ServiceManager)
+	if !ok {
+		return nil, errors.New("service manager not found in context")
+	}
+	return serviceManager, nil
+}
+
 // Config 通用配置接口
 type Config interface {
 	GetString(key string) string
@@ -2,30 +2,51 @@ package widget
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"log"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/linzi007/glance-china/internal/i18n"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // BilibiliVideosWidget Bilibili视频组件
 type BilibiliVideosWidget struct {
-	ChineseWidget
+	ChineseWidget `yaml:",inline"`
 	UPMasters     []BilibiliUPMaster `yaml:"up-masters"`
 	Limit         int                `yaml:"limit"`
+	PerUPLimit    int                `yaml:"per-up-limit"` // 每个UP主最多展示的视频数，默认只受 limit 限制
+	Sort          string             `yaml:"sort"`         // newest（默认）、views 或 round-robin
+	Concurrency   int                `yaml:"concurrency"`  // 同时请求上游的最大数量，默认 4
+	StatsLimit    int                `yaml:"stats-limit"`  // 为前几个视频获取点赞、投币、收藏数，每个视频一次请求，默认 10，-1 不获取
 	Style         string             `yaml:"style"`
 	CollapseAfter int                `yaml:"collapse-after"`
 }
 
 type BilibiliUPMaster struct {
-	UID  string `yaml:"uid"`
-	Name string `yaml:"name"`
+	UID   string `yaml:"uid"`
+	Name  string `yaml:"name"`
+	Limit int    `yaml:"limit"` // 覆盖组件的 per-up-limit
 }
 
+// 多个UP主的视频合并方式
+const (
+	bilibiliSortNewest     = "newest"      // 按发布时间倒序
+	bilibiliSortViews      = "views"       // 按播放量倒序
+	bilibiliSortRoundRobin = "round-robin" // 按配置顺序轮流取各UP主的最新视频
+)
+
+const (
+	// bilibiliDefaultConcurrency 默认同时请求上游的最大数量
+	bilibiliDefaultConcurrency = 4
+	// bilibiliDefaultStatsLimit 默认获取统计数据的视频数
+	bilibiliDefaultStatsLimit = 10
+)
+
 type BilibiliVideoData struct {
 	ID          string    `json:"id"`
 	Title       string    `json:"title"`
@@ -34,34 +55,24 @@ type BilibiliVideoData struct {
 	VideoURL    string    `json:"video_url"`
 	Thumbnail   string    `json:"thumbnail"`
 	Duration    string    `json:"duration"`
+	BVID        string    `json:"bvid"`
 	ViewCount   int64     `json:"view_count"`
+	DanmakuCount  int64   `json:"danmaku_count"`
+	CommentCount  int64   `json:"comment_count"`
+	LikeCount     int64   `json:"like_count"`
+	CoinCount     int64   `json:"coin_count"`
+	FavoriteCount int64   `json:"favorite_count"`
+	HasStats      bool    `json:"has_stats"` // 点赞、投币、收藏数是否已从统计接口获取
 	PublishedAt time.Time `json:"published_at"`
 	Source      string    `json:"source"`
 	ViewCountFormatted   string `json:"view_count_formatted"`
+	LikeCountFormatted     string `json:"like_count_formatted"`
+	CoinCountFormatted     string `json:"coin_count_formatted"`
+	FavoriteCountFormatted string `json:"favorite_count_formatted"`
 	PublishedAtFormatted string `json:"published_at_formatted"`
 	DurationFormatted    string `json:"duration_formatted"`
 }
 
-type BilibiliAPIResponse struct {
-	Code    int `json:"code"`
-	Message string `json:"message"`
-	Data    struct {
-		List struct {
-			Vlist []struct {
-				Aid         int64  `json:"aid"`
-				Title       string `json:"title"`
-				Description string `json:"description"`
-				Author      string `json:"author"`
-				Mid         int64  `json:"mid"`
-				Created     int64  `json:"created"`
-				Length      string `json:"length"`
-				VideoReview int64  `json:"video_review"`
-				Pic         string `json:"pic"`
-			} `json:"vlist"`
-		} `json:"list"`
-	} `json:"data"`
-}
-
 func NewBilibiliVideosWidget() *BilibiliVideosWidget {
 	return &BilibiliVideosWidget{
 		ChineseWidget: ChineseWidget{
@@ -72,45 +83,80 @@ func NewBilibiliVideosWidget() *BilibiliVideosWidget {
 			APISource: "bilibili",
 		},
 		Limit:         10,
+		Sort:          bilibiliSortNewest,
 		Style:         "horizontal-cards",
 		CollapseAfter: 5,
 	}
 }
 
 func (b *BilibiliVideosWidget) GetData(ctx context.Context, config Config) (interface{}, error) {
-	if locale := ctx.Value("locale"); locale != nil {
-		b.InitLocalizer(locale.(string))
+	serviceManager, err := serviceManagerFrom(ctx)
+	if err != nil {
+		return nil, err
 	}
 	
-	var allVideos []BilibiliVideoData
+	client, err := b.serviceClient(serviceManager, "bilibili")
+	if err != nil {
+		return nil, err
+	}
+	bilibiliClient, ok := client.(*service.BilibiliClient)
+	if !ok {
+		return nil, fmt.Errorf("api-source %s is not a bilibili source", b.APISource)
+	}
 	
-	for _, upMaster := range b.UPMasters {
-		videos, err := b.fetchUPMasterVideos(ctx, upMaster)
+	// 并发获取各UP主的视频，结果按配置顺序保存
+	perUP := make([][]BilibiliVideoData, len(b.UPMasters))
+	errs := make([]error, len(b.UPMasters))
+	forEachLimit(len(b.UPMasters), b.concurrency(), func(i int) {
+		perUP[i], errs[i] = b.fetchUPMasterVideos(ctx, bilibiliClient, b.UPMasters[i])
+	})
+	
+	// 个别UP主获取失败时记录日志并在组件中提示，全部失败时返回第一个错误
+	var failed []string
+	for i, err := range errs {
 		if err != nil {
-			continue
+			log.Printf("failed to fetch bilibili videos of %s (%s): %v", b.UPMasters[i].Name, b.UPMasters[i].UID, err)
+			failed = append(failed, b.UPMasters[i].Name)
 		}
-		allVideos = append(allVideos, videos...)
+	}
+	if len(failed) > 0 && len(failed) == len(b.UPMasters) {
+		return nil, errs[0]
 	}
 	
-	if len(allVideos) > b.Limit {
-		allVideos = allVideos[:b.Limit]
+	allVideos := b.mergeVideos(perUP)
+	b.fillStats(ctx, bilibiliClient, allVideos)
+	// 统计数据会更新播放量，按播放量排序时填充后重新排序
+	if b.Sort == bilibiliSortViews {
+		b.sortVideos(allVideos)
 	}
 	
-	localizer := b.GetLocalizer()
+	localizer := b.localizerFrom(ctx)
 	for i := range allVideos {
 		allVideos[i].ViewCountFormatted = localizer.FormatNumber(allVideos[i].ViewCount)
+		allVideos[i].LikeCountFormatted = localizer.FormatNumber(allVideos[i].LikeCount)
+		allVideos[i].CoinCountFormatted = localizer.FormatNumber(allVideos[i].CoinCount)
+		allVideos[i].FavoriteCountFormatted = localizer.FormatNumber(allVideos[i].FavoriteCount)
 		allVideos[i].PublishedAtFormatted = localizer.FormatRelativeTime(allVideos[i].PublishedAt)
 		allVideos[i].DurationFormatted = b.formatDuration(allVideos[i].Duration, localizer)
 	}
 	
+	partial := ""
+	if len(failed) > 0 {
+		partial = localizer.T("error.partial_up_masters", strings.Join(failed, ", "))
+	}
+	
 	return map[string]interface{}{
 		"videos":        allVideos,
+		"partial":       partial,
 		"style":         b.Style,
 		"collapse_after": b.CollapseAfter,
-		"title":         b.getLocalizedTitle(),
+		"title":         b.getLocalizedTitle(localizer),
 		"locale":        localizer.GetLocale(),
 		"labels": map[string]string{
 			"views":     localizer.T("number.views"),
+			"likes":     localizer.T("number.likes"),
+			"coins":     localizer.T("number.coins"),
+			"favorites": localizer.T("number.favorites"),
 			"published": localizer.T("video.published"),
 			"duration":  localizer.T("video.duration"),
 			"author":    localizer.T("video.author"),
@@ -118,55 +164,172 @@ func (b *BilibiliVideosWidget) GetData(ctx context.Context, config Config) (inte
 	}, nil
 }
 
-func (b *BilibiliVideosWidget) fetchUPMasterVideos(ctx context.Context, upMaster BilibiliUPMaster) ([]BilibiliVideoData, error) {
-	url := fmt.Sprintf("https://api.bilibili.com/x/space/arc/search?mid=%s&ps=10&tid=0&pn=1&keyword=&order=pubdate", upMaster.UID)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// fetchUPMasterVideos 获取UP主最新的视频，数量不超过该UP主的上限
+func (b *BilibiliVideosWidget) fetchUPMasterVideos(ctx context.Context, client *service.BilibiliClient, upMaster BilibiliUPMaster) ([]BilibiliVideoData, error) {
+	vlist, err := client.GetUPMasterVideos(ctx, upMaster.UID, b.upMasterLimit(upMaster))
 	if err != nil {
 		return nil, err
 	}
 	
-	// 设置必要的请求头
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Referer", "https://www.bilibili.com")
-	
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	var videos []BilibiliVideoData
+	for _, video := range vlist {
+		videoURL := fmt.Sprintf("https://www.bilibili.com/video/av%d", video.Aid)
+		if video.Bvid != "" {
+			videoURL = "https://www.bilibili.com/video/" + video.Bvid
+		}
+		
+		videos = append(videos, BilibiliVideoData{
+			ID:           strconv.FormatInt(video.Aid, 10),
+			BVID:         video.Bvid,
+			Title:        video.Title,
+			Author:       upMaster.Name,
+			AuthorURL:    fmt.Sprintf("https://space.bilibili.com/%s", upMaster.UID),
+			VideoURL:     videoURL,
+			Thumbnail:    video.Pic,
+			Duration:     video.Length,
+			ViewCount:    int64(video.Play),
+			DanmakuCount: video.VideoReview,
+			CommentCount: video.Comment,
+			PublishedAt:  time.Unix(video.Created, 0),
+			Source:       "bilibili",
+		})
 	}
-	defer resp.Body.Close()
 	
-	var apiResp BilibiliAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, err
+	return videos, nil
+}
+
+// upMasterLimit 返回UP主最多展示的视频数：UP主的 limit 优先，其次是 per-up-limit，且不超过组件的 limit
+func (b *BilibiliVideosWidget) upMasterLimit(upMaster BilibiliUPMaster) int {
+	limit := upMaster.Limit
+	if limit <= 0 {
+		limit = b.PerUPLimit
+	}
+	if limit <= 0 || limit > b.Limit {
+		limit = b.Limit
 	}
+	return limit
+}
+
+// mergeVideos 按 sort 合并各UP主的视频并截取前 limit 个
+func (b *BilibiliVideosWidget) mergeVideos(perUP [][]BilibiliVideoData) []BilibiliVideoData {
+	var merged []BilibiliVideoData
 	
-	if apiResp.Code != 0 {
-		return nil, fmt.Errorf("bilibili API error: %s", apiResp.Message)
+	switch b.Sort {
+	case bilibiliSortRoundRobin:
+		for round := 0; ; round++ {
+			added := false
+			for _, videos := range perUP {
+				if round < len(videos) {
+					merged = append(merged, videos[round])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	default:
+		for _, videos := range perUP {
+			merged = append(merged, videos...)
+		}
+		b.sortVideos(merged)
 	}
 	
-	var videos []BilibiliVideoData
-	for _, video := range apiResp.Data.List.Vlist {
-		videos = append(videos, BilibiliVideoData{
-			ID:          strconv.FormatInt(video.Aid, 10),
-			Title:       video.Title,
-			Author:      upMaster.Name,
-			AuthorURL:   fmt.Sprintf("https://space.bilibili.com/%s", upMaster.UID),
-			VideoURL:    fmt.Sprintf("https://www.bilibili.com/video/av%d", video.Aid),
-			Thumbnail:   video.Pic,
-			Duration:    video.Length,
-			ViewCount:   video.VideoReview,
-			PublishedAt: time.Unix(video.Created, 0),
-			Source:      "bilibili",
-		})
+	if len(merged) > b.Limit {
+		merged = merged[:b.Limit]
+	}
+	return merged
+}
+
+// sortVideos 按发布时间倒序排列，sort 为 views 时按播放量倒序
+func (b *BilibiliVideosWidget) sortVideos(videos []BilibiliVideoData) {
+	sort.SliceStable(videos, func(i, j int) bool {
+		if b.Sort == bilibiliSortViews && videos[i].ViewCount != videos[j].ViewCount {
+			return videos[i].ViewCount > videos[j].ViewCount
+		}
+		return videos[i].PublishedAt.After(videos[j].PublishedAt)
+	})
+}
+
+// fillStats 并发获取前 stats-limit 个视频的点赞、投币、收藏数；其余视频和获取失败的视频
+// 只有视频列表中的播放、评论和弹幕数
+func (b *BilibiliVideosWidget) fillStats(ctx context.Context, client *service.BilibiliClient, videos []BilibiliVideoData) {
+	if limit := b.statsLimit(); len(videos) > limit {
+		videos = videos[:limit]
 	}
+	errs := make([]error, len(videos))
+	forEachLimit(len(videos), b.concurrency(), func(i int) {
+		video := &videos[i]
+		if video.BVID == "" {
+			return
+		}
+		
+		stat, err := client.GetVideoStat(ctx, video.BVID)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		video.ViewCount = stat.View
+		video.DanmakuCount = stat.Danmaku
+		video.CommentCount = stat.Reply
+		video.LikeCount = stat.Like
+		video.CoinCount = stat.Coin
+		video.FavoriteCount = stat.Favorite
+		video.HasStats = true
+	})
 	
-	return videos, nil
+	var failed []string
+	var lastErr error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, videos[i].BVID)
+			lastErr = err
+		}
+	}
+	if len(failed) > 0 {
+		log.Printf("failed to fetch bilibili stats of %s, using list counts: %v", strings.Join(failed, ","), lastErr)
+	}
+}
+
+// concurrency 返回同时请求上游的最大数量
+func (b *BilibiliVideosWidget) concurrency() int {
+	if b.Concurrency <= 0 {
+		return bilibiliDefaultConcurrency
+	}
+	return b.Concurrency
+}
+
+// statsLimit 返回获取统计数据的视频数
+func (b *BilibiliVideosWidget) statsLimit() int {
+	if b.StatsLimit < 0 {
+		return 0
+	}
+	if b.StatsLimit == 0 {
+		return bilibiliDefaultStatsLimit
+	}
+	return b.StatsLimit
+}
+
+// forEachLimit 以最多 limit 个协程并发执行 fn(0) 到 fn(n-1)，全部完成后返回
+func forEachLimit(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
 }
 
 func (b *BilibiliVideosWidget) GetCacheKey(config Config) string {
-	return fmt.Sprintf("bilibili-videos:%d", len(b.UPMasters))
+	return CacheKey(b, config)
 }
 
 func (b *BilibiliVideosWidget) getTitle() string {
@@ -187,14 +350,20 @@ func (b *BilibiliVideosWidget) Validate(config Config) error {
 		}
 	}
 	
+	switch b.Sort {
+	case "", bilibiliSortNewest, bilibiliSortViews, bilibiliSortRoundRobin:
+	default:
+		return fmt.Errorf("不支持的排序方式: %s，可选 newest、views、round-robin", b.Sort)
+	}
+	
 	return nil
 }
 
-func (b *BilibiliVideosWidget) getLocalizedTitle() string {
+func (b *BilibiliVideosWidget) getLocalizedTitle(localizer *i18n.Localizer) string {
 	if b.Title != "" {
 		return b.Title
 	}
-	return b.T("widget.bilibili_videos")
+	return localizer.T("widget.bilibili_videos")
 }
 
 func (b *BilibiliVideosWidget) formatDuration(duration string, localizer *i18n.Localizer) string {
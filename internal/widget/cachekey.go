@@ -0,0 +1,86 @@
+package widget
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultLocale 未指定语言时组件使用的语言，与 InitLocalizer 一致
+const defaultLocale = "zh-CN"
+
+// ConfigMap 基于 map 的 Config 实现，用于向组件传递运行时参数（如当前语言 locale）
+type ConfigMap map[string]interface{}
+
+func (m ConfigMap) GetString(key string) string {
+	if value, ok := m[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (m ConfigMap) GetInt(key string) int {
+	if value, ok := m[key].(int); ok {
+		return value
+	}
+	return 0
+}
+
+func (m ConfigMap) GetBool(key string) bool {
+	if value, ok := m[key].(bool); ok {
+		return value
+	}
+	return false
+}
+
+func (m ConfigMap) GetStringSlice(key string) []string {
+	if value, ok := m[key].([]string); ok {
+		return value
+	}
+	return nil
+}
+
+// CacheKey 生成组件缓存 key：组件类型、当前语言和全部配置字段的规范化哈希。
+// 当前语言取自 config 中的 locale，组件自身的 locale 字段不参与哈希
+func CacheKey(w Widget, config Config) string {
+	locale := ""
+	if config != nil {
+		locale = config.GetString("locale")
+	}
+	if locale == "" {
+		locale = defaultLocale
+	}
+
+	hash, err := configHash(w)
+	if err != nil {
+		// 无法序列化时按实例区分，宁可不共享缓存也不能串用数据
+		hash = fmt.Sprintf("%p", w)
+	}
+
+	return fmt.Sprintf("%s:%s:%s", w.GetType(), locale, hash)
+}
+
+// configHash 按 YAML 字段名序列化组件配置，再以键排序的 JSON 计算哈希，结果与字段声明顺序无关
+func configHash(w Widget) (string, error) {
+	data, err := yaml.Marshal(w)
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+	delete(fields, "locale")
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:16]), nil
+}
@@ -3,14 +3,13 @@ package widget
 import (
 	"context"
 	"fmt"
-	"time"
 	
-	"github.com/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // DouyuLiveWidget 斗鱼直播组件
 type DouyuLiveWidget struct {
-	ChineseWidget
+	ChineseWidget `yaml:",inline"`
 	Rooms         []DouyuRoom `yaml:"rooms"`
 	Limit         int         `yaml:"limit"`
 	ShowOffline   bool        `yaml:"show-offline"`
@@ -38,14 +37,20 @@ func NewDouyuLiveWidget() *DouyuLiveWidget {
 }
 
 func (d *DouyuLiveWidget) GetData(ctx context.Context, config Config) (interface{}, error) {
-	serviceManager := ctx.Value("serviceManager").(*service.ServiceManager)
+	serviceManager, err := serviceManagerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
 	
-	client, err := serviceManager.GetClient("douyu")
+	client, err := d.serviceClient(serviceManager, "douyu")
 	if err != nil {
 		return nil, err
 	}
 	
-	douyuClient := client.(*service.DouyuClient)
+	douyuClient, ok := client.(*service.DouyuClient)
+	if !ok {
+		return nil, fmt.Errorf("api-source %s is not a douyu source", d.APISource)
+	}
 	
 	var roomIDs []string
 	for _, room := range d.Rooms {
@@ -82,7 +87,7 @@ func (d *DouyuLiveWidget) GetData(ctx context.Context, config Config) (interface
 }
 
 func (d *DouyuLiveWidget) GetCacheKey(config Config) string {
-	return fmt.Sprintf("douyu-live:%d", len(d.Rooms))
+	return CacheKey(d, config)
 }
 
 func (d *DouyuLiveWidget) getTitle() string {
@@ -2,16 +2,16 @@ package widget
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
+	
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // GiteeReposWidget Gitee仓库组件
 type GiteeReposWidget struct {
-	ChineseWidget
+	ChineseWidget `yaml:",inline"`
 	Repositories []string `yaml:"repositories"`
 	Token        string   `yaml:"token"`
 	ShowIssues   bool     `yaml:"show-issues"`
@@ -34,18 +34,6 @@ type GiteeRepoData struct {
 	ReleaseURL      string    `json:"release_url,omitempty"`
 }
 
-type GiteeAPIRepo struct {
-	Name        string `json:"name"`
-	FullName    string `json:"full_name"`
-	Description string `json:"description"`
-	HTMLURL     string `json:"html_url"`
-	Language    string `json:"language"`
-	StargazersCount int `json:"stargazers_count"`
-	ForksCount      int `json:"forks_count"`
-	OpenIssuesCount int `json:"open_issues_count"`
-	UpdatedAt       string `json:"updated_at"`
-}
-
 func NewGiteeReposWidget() *GiteeReposWidget {
 	return &GiteeReposWidget{
 		ChineseWidget: ChineseWidget{
@@ -62,10 +50,26 @@ func NewGiteeReposWidget() *GiteeReposWidget {
 }
 
 func (g *GiteeReposWidget) GetData(ctx context.Context, config Config) (interface{}, error) {
+	serviceManager, err := serviceManagerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	client, err := g.serviceClient(serviceManager, "gitee")
+	if err != nil {
+		return nil, err
+	}
+	baseClient, ok := client.(*service.GiteeClient)
+	if !ok {
+		return nil, fmt.Errorf("api-source %s is not a gitee source", g.APISource)
+	}
+	// 组件配置的 token 优先于 api-sources 中的 token
+	giteeClient := baseClient.WithToken(g.Token)
+	
 	var allRepos []GiteeRepoData
 	
 	for _, repo := range g.Repositories {
-		repoData, err := g.fetchRepository(ctx, repo)
+		repoData, err := g.fetchRepository(ctx, giteeClient, repo)
 		if err != nil {
 			// 记录错误但继续处理其他仓库
 			continue
@@ -86,56 +90,32 @@ func (g *GiteeReposWidget) GetData(ctx context.Context, config Config) (interfac
 	}, nil
 }
 
-func (g *GiteeReposWidget) fetchRepository(ctx context.Context, repoPath string) (*GiteeRepoData, error) {
+func (g *GiteeReposWidget) fetchRepository(ctx context.Context, client *service.GiteeClient, repoPath string) (*GiteeRepoData, error) {
 	// repoPath 格式: owner/repo
 	parts := strings.Split(repoPath, "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("invalid repository format: %s", repoPath)
 	}
 	
-	url := fmt.Sprintf("https://gitee.com/api/v5/repos/%s", repoPath)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	
-	// 添加认证token（如果提供）
-	if g.Token != "" {
-		req.Header.Set("Authorization", "token "+g.Token)
-	}
-	
-	req.Header.Set("User-Agent", "Glance-China/1.0")
-	
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	repo, err := client.GetRepository(ctx, repoPath)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	
-	var apiRepo GiteeAPIRepo
-	if err := json.NewDecoder(resp.Body).Decode(&apiRepo); err != nil {
-		return nil, err
-	}
-	
-	// 解析更新时间
-	updatedAt, _ := time.Parse(time.RFC3339, apiRepo.UpdatedAt)
 	
 	repoData := &GiteeRepoData{
-		Name:         apiRepo.Name,
-		FullName:     apiRepo.FullName,
-		Description:  apiRepo.Description,
-		URL:          apiRepo.HTMLURL,
-		Language:     apiRepo.Language,
-		Stars:        apiRepo.StargazersCount,
-		Forks:        apiRepo.ForksCount,
-		Issues:       apiRepo.OpenIssuesCount,
-		LastCommit:   updatedAt,
+		Name:         repo.Name,
+		FullName:     repo.FullName,
+		Description:  repo.Description,
+		URL:          repo.HTMLURL,
+		Language:     repo.Language,
+		Stars:        repo.StargazersCount,
+		Forks:        repo.ForksCount,
+		Issues:       repo.OpenIssuesCount,
+		LastCommit:   repo.UpdatedAt,
 	}
 	
 	// 获取最新发布版本（如果需要）
-	if release, err := g.fetchLatestRelease(ctx, repoPath); err == nil {
+	if release, err := client.GetLatestRelease(ctx, repoPath); err == nil {
 		repoData.LatestRelease = release.TagName
 		repoData.ReleaseURL = release.HTMLURL
 	}
@@ -143,40 +123,8 @@ func (g *GiteeReposWidget) fetchRepository(ctx context.Context, repoPath string)
 	return repoData, nil
 }
 
-type GiteeRelease struct {
-	TagName string `json:"tag_name"`
-	HTMLURL string `json:"html_url"`
-}
-
-func (g *GiteeReposWidget) fetchLatestRelease(ctx context.Context, repoPath string) (*GiteeRelease, error) {
-	url := fmt.Sprintf("https://gitee.com/api/v5/repos/%s/releases/latest", repoPath)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	
-	if g.Token != "" {
-		req.Header.Set("Authorization", "token "+g.Token)
-	}
-	
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	var release GiteeRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
-	}
-	
-	return &release, nil
-}
-
 func (g *GiteeReposWidget) GetCacheKey(config Config) string {
-	return fmt.Sprintf("gitee-repos:%d", len(g.Repositories))
+	return CacheKey(g, config)
 }
 
 func (g *GiteeReposWidget) getTitle() string {
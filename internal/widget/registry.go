@@ -35,6 +35,15 @@ func CreateWidget(widgetType string) (Widget, error) {
 	return constructor(), nil
 }
 
+// IsRegistered 检查组件类型是否已注册
+func IsRegistered(widgetType string) bool {
+	globalRegistry.mu.RLock()
+	defer globalRegistry.mu.RUnlock()
+	
+	_, exists := globalRegistry.widgets[widgetType]
+	return exists
+}
+
 // GetRegisteredWidgets 获取所有已注册的组件类型
 func GetRegisteredWidgets() []string {
 	globalRegistry.mu.RLock()
@@ -60,4 +69,12 @@ func init() {
 	RegisterWidget("gitee-repos", func() Widget {
 		return NewGiteeReposWidget()
 	})
+	
+	RegisterWidget("weibo-hot-search", func() Widget {
+		return NewWeiboHotSearchWidget()
+	})
+	
+	RegisterWidget("douyu-live", func() Widget {
+		return NewDouyuLiveWidget()
+	})
 }
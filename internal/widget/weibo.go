@@ -3,14 +3,13 @@ package widget
 import (
 	"context"
 	"fmt"
-	"time"
 	
-	"github.com/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // WeiboHotSearchWidget 微博热搜组件
 type WeiboHotSearchWidget struct {
-	ChineseWidget
+	ChineseWidget `yaml:",inline"`
 	Categories    []string `yaml:"categories"`
 	Limit         int      `yaml:"limit"`
 	ShowIcons     bool     `yaml:"show-icons"`
@@ -35,14 +34,20 @@ func NewWeiboHotSearchWidget() *WeiboHotSearchWidget {
 
 func (w *WeiboHotSearchWidget) GetData(ctx context.Context, config Config) (interface{}, error) {
 	// 获取服务管理器
-	serviceManager := ctx.Value("serviceManager").(*service.ServiceManager)
+	serviceManager, err := serviceManagerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
 	
-	client, err := serviceManager.GetClient("weibo")
+	client, err := w.serviceClient(serviceManager, "weibo")
 	if err != nil {
 		return nil, err
 	}
 	
-	weiboClient := client.(*service.WeiboClient)
+	weiboClient, ok := client.(*service.WeiboClient)
+	if !ok {
+		return nil, fmt.Errorf("api-source %s is not a weibo source", w.APISource)
+	}
 	hotSearches, err := weiboClient.GetHotSearch(ctx)
 	if err != nil {
 		return nil, err
@@ -62,7 +67,7 @@ func (w *WeiboHotSearchWidget) GetData(ctx context.Context, config Config) (inte
 }
 
 func (w *WeiboHotSearchWidget) GetCacheKey(config Config) string {
-	return fmt.Sprintf("weibo-hot-search:%v", w.Categories)
+	return CacheKey(w, config)
 }
 
 func (w *WeiboHotSearchWidget) getTitle() string {
@@ -2,17 +2,16 @@ package widget
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"time"
 
-	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/linzi007/glance-china/internal/i18n"
+	"github.com/linzi007/glance-china/internal/service"
 )
 
 // ZhihuTrendingWidget 知乎热榜组件
 type ZhihuTrendingWidget struct {
-	ChineseWidget
+	ChineseWidget `yaml:",inline"`
 	Categories    []string `yaml:"categories"`
 	Limit         int      `yaml:"limit"`
 	ShowImages    bool     `yaml:"show-images"`
@@ -36,24 +35,6 @@ type ZhihuTrendingData struct {
 	CategoryLocalized   string    `json:"category_localized"`
 }
 
-type ZhihuAPIResponse struct {
-	Data []struct {
-		Target struct {
-			ID       int64  `json:"id"`
-			Title    string `json:"title"`
-			Excerpt  string `json:"excerpt"`
-			URL      string `json:"url"`
-			Author   struct {
-				Name      string `json:"name"`
-				URL       string `json:"url"`
-				AvatarURL string `json:"avatar_url"`
-			} `json:"author"`
-		} `json:"target"`
-		DetailText string `json:"detail_text"`
-		HeatValue  int64  `json:"heat_value"`
-	} `json:"data"`
-}
-
 func NewZhihuTrendingWidget() *ZhihuTrendingWidget {
 	return &ZhihuTrendingWidget{
 		ChineseWidget: ChineseWidget{
@@ -71,10 +52,6 @@ func NewZhihuTrendingWidget() *ZhihuTrendingWidget {
 }
 
 func (z *ZhihuTrendingWidget) GetData(ctx context.Context, config Config) (interface{}, error) {
-	if locale := ctx.Value("locale"); locale != nil {
-		z.InitLocalizer(locale.(string))
-	}
-
 	trending, err := z.fetchTrending(ctx)
 	if err != nil {
 		return nil, err
@@ -88,7 +65,7 @@ func (z *ZhihuTrendingWidget) GetData(ctx context.Context, config Config) (inter
 		trending = trending[:z.Limit]
 	}
 
-	localizer := z.GetLocalizer()
+	localizer := z.localizerFrom(ctx)
 	for i := range trending {
 		trending[i].HeatValueFormatted = localizer.FormatNumber(trending[i].HeatValue)
 		trending[i].UpdatedAtFormatted = localizer.FormatRelativeTime(trending[i].UpdatedAt)
@@ -99,7 +76,7 @@ func (z *ZhihuTrendingWidget) GetData(ctx context.Context, config Config) (inter
 		"trending":       trending,
 		"show_images":    z.ShowImages,
 		"collapse_after": z.CollapseAfter,
-		"title":          z.getLocalizedTitle(),
+		"title":          z.getLocalizedTitle(localizer),
 		"locale":         localizer.GetLocale(),
 		"labels": map[string]string{
 			"heat":      localizer.T("热度"),
@@ -111,31 +88,28 @@ func (z *ZhihuTrendingWidget) GetData(ctx context.Context, config Config) (inter
 }
 
 func (z *ZhihuTrendingWidget) fetchTrending(ctx context.Context) ([]ZhihuTrendingData, error) {
-	// 使用知乎热榜API（这里使用模拟的API端点）
-	url := "https://www.zhihu.com/api/v3/feed/topstory/hot-lists/total"
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	serviceManager, err := serviceManagerFrom(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Referer", "https://www.zhihu.com")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	client, err := z.serviceClient(serviceManager, "zhihu")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	zhihuClient, ok := client.(*service.ZhihuClient)
+	if !ok {
+		return nil, fmt.Errorf("api-source %s is not a zhihu source", z.APISource)
+	}
 
-	var apiResp ZhihuAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+	// 分类过滤在本地进行，需要获取完整热榜
+	hotList, err := zhihuClient.GetHotList(ctx, 50)
+	if err != nil {
 		return nil, err
 	}
 
 	var trending []ZhihuTrendingData
-	for _, item := range apiResp.Data {
+	for _, item := range hotList {
 		trending = append(trending, ZhihuTrendingData{
 			ID:          fmt.Sprintf("%d", item.Target.ID),
 			Title:       item.Target.Title,
@@ -145,6 +119,7 @@ func (z *ZhihuTrendingWidget) fetchTrending(ctx context.Context) ([]ZhihuTrendin
 			AuthorURL:   item.Target.Author.URL,
 			Image:       item.Target.Author.AvatarURL,
 			HeatValue:   item.HeatValue,
+			AnswerCount: item.Target.AnswerCount,
 			UpdatedAt:   time.Now(),
 			Category:    "general",
 		})
@@ -171,7 +146,7 @@ func (z *ZhihuTrendingWidget) filterByCategories(trending []ZhihuTrendingData) [
 }
 
 func (z *ZhihuTrendingWidget) GetCacheKey(config Config) string {
-	return fmt.Sprintf("zhihu-trending:%v", z.Categories)
+	return CacheKey(z, config)
 }
 
 func (z *ZhihuTrendingWidget) getTitle() string {
@@ -197,9 +172,9 @@ func (z *ZhihuTrendingWidget) localizeCategory(category string, localizer *i18n.
 	return translated
 }
 
-func (z *ZhihuTrendingWidget) getLocalizedTitle() string {
+func (z *ZhihuTrendingWidget) getLocalizedTitle(localizer *i18n.Localizer) string {
 	if z.Title != "" {
 		return z.Title
 	}
-	return z.T("widget.zhihu_trending")
+	return localizer.T("widget.zhihu_trending")
 }
@@ -6,9 +6,8 @@ import (
 	"log"
 	"time"
 	
-	"github.com/glance-china/internal/performance"
-	"github.com/glance-china/internal/service"
-	"github.com/glance-china/internal/widget"
+	"github.com/linzi007/glance-china/internal/performance"
+	"github.com/linzi007/glance-china/internal/widget"
 )
 
 func main() {
@@ -6,8 +6,8 @@ import (
 	"testing"
 	"time"
 	
-	"github.com/glance-china/internal/performance"
-	"github.com/glance-china/internal/widget"
+	"github.com/linzi007/glance-china/internal/performance"
+	"github.com/linzi007/glance-china/internal/widget"
 )
 
 // TestBilibiliWidgetBenchmark Bilibili组件基准测试
@@ -27,13 +27,14 @@ func TestBilibiliWidgetBenchmark(t *testing.T) {
 	bilibiliWidget.UPMasters = []widget.BilibiliUPMaster{
 		{UID: "123456", Name: "测试UP主"},
 	}
+	ctx, _ := newFakeUpstream(t).serviceContext()
 	
 	testFunc := func(ctx context.Context) error {
 		_, err := bilibiliWidget.GetData(ctx, &mockConfig{})
 		return err
 	}
 	
-	result, err := benchmarker.RunBenchmark(context.Background(), testFunc)
+	result, err := benchmarker.RunBenchmark(ctx, testFunc)
 	if err != nil {
 		t.Fatalf("基准测试失败: %v", err)
 	}
@@ -67,13 +68,14 @@ func TestZhihuWidgetBenchmark(t *testing.T) {
 	benchmarker := performance.NewBenchmarker(config)
 	
 	zhihuWidget := widget.NewZhihuTrendingWidget()
+	ctx, _ := newFakeUpstream(t).serviceContext()
 	
 	testFunc := func(ctx context.Context) error {
 		_, err := zhihuWidget.GetData(ctx, &mockConfig{})
 		return err
 	}
 	
-	result, err := benchmarker.RunBenchmark(context.Background(), testFunc)
+	result, err := benchmarker.RunBenchmark(ctx, testFunc)
 	if err != nil {
 		t.Fatalf("基准测试失败: %v", err)
 	}
@@ -102,6 +104,7 @@ func TestConcurrentWidgetLoad(t *testing.T) {
 		widget.NewZhihuTrendingWidget(),
 		widget.NewGiteeReposWidget(),
 	}
+	ctx, _ := newFakeUpstream(t).serviceContext()
 	
 	testFunc := func(ctx context.Context) error {
 		// 随机选择一个组件进行测试
@@ -110,7 +113,7 @@ func TestConcurrentWidgetLoad(t *testing.T) {
 		return err
 	}
 	
-	result, err := benchmarker.RunBenchmark(context.Background(), testFunc)
+	result, err := benchmarker.RunBenchmark(ctx, testFunc)
 	if err != nil {
 		t.Fatalf("并发测试失败: %v", err)
 	}
@@ -0,0 +1,282 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// 录制的 nav 响应中 wbi_img 对应的混合密钥
+const fixtureMixinKey = "ea1db124af3c7062474693fa704f4ff8"
+
+// bilibiliFixture 读取 testdata/bilibili 下录制的接口响应
+func bilibiliFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "bilibili", name))
+	if err != nil {
+		t.Fatalf("读取 fixture 失败: %v", err)
+	}
+	return body
+}
+
+// bilibiliUpstream 模拟 Bilibili 接口：返回录制的响应，校验空间接口的 WBI 签名和 buvid3 Cookie，
+// search 为空时返回录制的视频列表
+type bilibiliUpstream struct {
+	*httptest.Server
+	search   [][]byte // 依次返回的空间接口响应
+	spiFails bool
+	navGate  chan struct{} // 非空时 nav 请求等到关闭后才响应
+	navWaits int32         // 正在等待 navGate 的 nav 请求数
+	hits     map[string]int
+	cookies  []map[string]string // 每次空间接口请求携带的 Cookie
+	mu       sync.Mutex
+}
+
+func newBilibiliUpstream(t *testing.T) *bilibiliUpstream {
+	t.Helper()
+
+	u := &bilibiliUpstream{hits: make(map[string]int)}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/x/web-interface/nav" && u.navGate != nil {
+			atomic.AddInt32(&u.navWaits, 1)
+			<-u.navGate
+		}
+
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		u.hits[r.URL.Path]++
+
+		switch r.URL.Path {
+		case "/x/web-interface/nav":
+			w.Write(bilibiliFixture(t, "nav.json"))
+		case "/x/frontend/finger/spi":
+			if u.spiFails {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(bilibiliFixture(t, "spi.json"))
+		case "/x/space/wbi/arc/search":
+			cookies := make(map[string]string)
+			for _, cookie := range r.Cookies() {
+				cookies[cookie.Name] = cookie.Value
+			}
+			u.cookies = append(u.cookies, cookies)
+
+			if !validWBISignature(r) || cookies["buvid3"] == "" {
+				w.Write(bilibiliFixture(t, "risk_control.json"))
+				return
+			}
+			if len(u.search) > 0 {
+				w.Write(u.search[0])
+				u.search = u.search[1:]
+				return
+			}
+			w.Write(bilibiliFixture(t, "arc_search.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(u.Close)
+
+	return u
+}
+
+func (u *bilibiliUpstream) count(path string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.hits[path]
+}
+
+// validWBISignature 按录制的密钥重新计算签名，与请求中的 w_rid 比较
+func validWBISignature(r *http.Request) bool {
+	query := r.URL.Query()
+	wts, err := strconv.ParseInt(query.Get("wts"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	params := make(map[string]interface{})
+	for key := range query {
+		if key != "w_rid" && key != "wts" {
+			params[key] = query.Get(key)
+		}
+	}
+	return service.SignWBIParams(params, fixtureMixinKey, time.Unix(wts, 0))["w_rid"] == query.Get("w_rid")
+}
+
+// TestWBISignature 按公开的示例计算混合密钥和签名
+func TestWBISignature(t *testing.T) {
+	key := service.WBIMixinKey(
+		"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png",
+		"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png",
+	)
+	if key != fixtureMixinKey {
+		t.Fatalf("混合密钥错误: %s", key)
+	}
+
+	signed := service.SignWBIParams(map[string]interface{}{"foo": "114", "bar": "514", "zab": 1919810}, key, time.Unix(1702204169, 0))
+	if signed["wts"] != "1702204169" || signed["w_rid"] != "8f6f2b5b3d485fe1886cec6a0be8c5d4" {
+		t.Errorf("签名错误: %v", signed)
+	}
+
+	// 值中的 !'()* 不参与签名
+	filtered := service.SignWBIParams(map[string]interface{}{"keyword": "a(b)!'*"}, key, time.Unix(1702204169, 0))
+	plain := service.SignWBIParams(map[string]interface{}{"keyword": "ab"}, key, time.Unix(1702204169, 0))
+	if filtered["w_rid"] != plain["w_rid"] || filtered["keyword"] != "ab" {
+		t.Errorf("应去除特殊字符后签名: %v %v", filtered, plain)
+	}
+}
+
+// TestBilibiliClientSignsSpaceRequests 空间接口请求带 WBI 签名、buvid3 和配置的 SESSDATA，密钥和 buvid3 只获取一次
+func TestBilibiliClientSignsSpaceRequests(t *testing.T) {
+	upstream := newBilibiliUpstream(t)
+	client := service.NewBilibiliClient(service.APISourceConfig{
+		BaseURL: upstream.URL,
+		Timeout: 5 * time.Second,
+		Cookies: map[string]string{"SESSDATA": "session-token"},
+	})
+
+	for i := 0; i < 2; i++ {
+		videos, err := client.GetUPMasterVideos(context.Background(), "546195", 2)
+		if err != nil {
+			t.Fatalf("获取视频失败: %v", err)
+		}
+		if len(videos) != 2 || videos[0].Title != "新视频" || videos[0].Aid != 170001 {
+			t.Fatalf("视频数据错误: %+v", videos)
+		}
+	}
+
+	if got := upstream.count("/x/web-interface/nav"); got != 1 {
+		t.Errorf("WBI 密钥应缓存, nav 请求 %d 次", got)
+	}
+	if got := upstream.count("/x/frontend/finger/spi"); got != 1 {
+		t.Errorf("buvid3 应保存在 Cookie Jar 中, spi 请求 %d 次", got)
+	}
+	for _, cookies := range upstream.cookies {
+		if cookies["buvid3"] != "5D6B2C3E-1A4F-8B7C-9D0E-123456789ABC34567infoc" || cookies["SESSDATA"] != "session-token" {
+			t.Errorf("Cookie 错误: %v", cookies)
+		}
+	}
+}
+
+// TestBilibiliClientRefreshesKeyAfterRejection 签名被拒绝（-352）时清除缓存的密钥，下次请求重新获取
+func TestBilibiliClientRefreshesKeyAfterRejection(t *testing.T) {
+	upstream := newBilibiliUpstream(t)
+	upstream.search = [][]byte{bilibiliFixture(t, "risk_control.json")}
+	client := service.NewBilibiliClient(service.APISourceConfig{BaseURL: upstream.URL, Timeout: 5 * time.Second})
+
+	if _, err := client.GetUPMasterVideos(context.Background(), "546195", 2); err == nil {
+		t.Fatal("-352 应返回错误")
+	}
+	if _, err := client.GetUPMasterVideos(context.Background(), "546195", 2); err != nil {
+		t.Fatalf("重新获取密钥后应成功: %v", err)
+	}
+	if got := upstream.count("/x/web-interface/nav"); got != 2 {
+		t.Errorf("应重新请求 nav, got %d", got)
+	}
+}
+
+// TestBilibiliClientCoalescesKeyRefresh 并发请求只获取一次 WBI 密钥，等待密钥的请求在自身超时后返回，不被其他请求的获取阻塞
+func TestBilibiliClientCoalescesKeyRefresh(t *testing.T) {
+	upstream := newBilibiliUpstream(t)
+	upstream.navGate = make(chan struct{})
+	client := service.NewBilibiliClient(service.APISourceConfig{BaseURL: upstream.URL, Timeout: 5 * time.Second})
+
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := client.GetUPMasterVideos(context.Background(), "546195", 2)
+			results <- err
+		}()
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&upstream.navWaits) == 1 }, "应发起 nav 请求")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	timedOut := make(chan error, 1)
+	go func() {
+		_, err := client.GetUPMasterVideos(ctx, "546195", 2)
+		timedOut <- err
+	}()
+	select {
+	case err := <-timedOut:
+		if err == nil {
+			t.Error("密钥未获取到时超时的请求应返回错误")
+		}
+	case <-time.After(time.Second):
+		t.Error("等待密钥的请求应在自身超时后返回")
+	}
+
+	close(upstream.navGate)
+	for i := 0; i < 3; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("获取视频失败: %v", err)
+		}
+	}
+	if got := upstream.count("/x/web-interface/nav"); got != 1 {
+		t.Errorf("并发请求应合并密钥获取, nav 请求 %d 次", got)
+	}
+}
+
+// TestBilibiliClientGeneratesBuvidLocally spi 接口不可用时在本地生成 buvid3
+func TestBilibiliClientGeneratesBuvidLocally(t *testing.T) {
+	upstream := newBilibiliUpstream(t)
+	upstream.spiFails = true
+	client := service.NewBilibiliClient(service.APISourceConfig{BaseURL: upstream.URL, Timeout: 5 * time.Second})
+
+	if _, err := client.GetUPMasterVideos(context.Background(), "546195", 2); err != nil {
+		t.Fatalf("获取视频失败: %v", err)
+	}
+	if buvid := upstream.cookies[0]["buvid3"]; !strings.HasSuffix(buvid, "infoc") || len(buvid) != 46 {
+		t.Errorf("本地生成的 buvid3 格式错误: %q", buvid)
+	}
+}
+
+// TestBilibiliSessionSurvivesReload 热更新后 bilibili 源配置未变化时沿用客户端的 WBI 密钥和 buvid3，配置变化后重新获取
+func TestBilibiliSessionSurvivesReload(t *testing.T) {
+	upstream := newBilibiliUpstream(t)
+	config := func(referer string) *service.Config {
+		return &service.Config{
+			APISources: map[string]service.APISourceConfig{
+				"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Headers: map[string]string{"Referer": referer}},
+			},
+			RateLimit: service.RateLimitConfig{DefaultLimit: 1000},
+		}
+	}
+	sm := service.NewServiceManager(config("https://www.bilibili.com"))
+	fetch := func() {
+		t.Helper()
+		client, err := sm.GetClient("bilibili")
+		if err != nil {
+			t.Fatalf("获取客户端失败: %v", err)
+		}
+		if _, err := client.(*service.BilibiliClient).GetUPMasterVideos(context.Background(), "546195", 2); err != nil {
+			t.Fatalf("获取视频失败: %v", err)
+		}
+	}
+
+	fetch()
+	sm.UpdateConfig(config("https://www.bilibili.com"))
+	fetch()
+	if nav, spi := upstream.count("/x/web-interface/nav"), upstream.count("/x/frontend/finger/spi"); nav != 1 || spi != 1 {
+		t.Errorf("配置未变化时应沿用会话, nav 请求 %d 次, spi 请求 %d 次", nav, spi)
+	}
+
+	sm.UpdateConfig(config("https://space.bilibili.com"))
+	fetch()
+	if nav, spi := upstream.count("/x/web-interface/nav"), upstream.count("/x/frontend/finger/spi"); nav != 2 || spi != 2 {
+		t.Errorf("配置变化后应重建会话, nav 请求 %d 次, spi 请求 %d 次", nav, spi)
+	}
+}
@@ -0,0 +1,381 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// upVideo 模拟空间接口中的一个视频，play 为原始 JSON，隐藏播放量时为 "--"
+type upVideo struct {
+	aid     int64
+	created int64
+	play    string
+}
+
+var upVideos = map[string][]upVideo{
+	"1": {{aid: 11, created: 1700000300, play: "10"}, {aid: 12, created: 1700000100, play: "500"}},
+	"2": {{aid: 21, created: 1700000200, play: "1000"}, {aid: 22, created: 1700000000, play: `"--"`}},
+}
+
+// videoUpstream 按 mid 返回 upVideos 中的视频并分页，mid 为 many 时共有 120 个视频；
+// barrier 大于 0 时空间接口等到同时有 barrier 个请求才响应，delay 大于 0 时每个请求延迟响应
+type videoUpstream struct {
+	*httptest.Server
+	pages    []string // 空间接口请求的 mid:pn:ps
+	statFail bool
+	barrier  int
+	waiting  int
+	release  chan struct{}
+	delay    time.Duration
+	active   int
+	peak     int // 同时处理的最大请求数
+	stats    int // 统计接口的请求次数
+	mu       sync.Mutex
+}
+
+func newVideoUpstream(t *testing.T) *videoUpstream {
+	t.Helper()
+
+	u := &videoUpstream{release: make(chan struct{})}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		u.enter()
+		defer u.leave()
+
+		switch r.URL.Path {
+		case "/x/web-interface/nav":
+			w.Write(bilibiliFixture(t, "nav.json"))
+		case "/x/frontend/finger/spi":
+			w.Write(bilibiliFixture(t, "spi.json"))
+		case "/x/space/wbi/arc/search":
+			if !validWBISignature(r) {
+				w.Write(bilibiliFixture(t, "risk_control.json"))
+				return
+			}
+			if query.Get("mid") == "broken" {
+				http.Error(w, "unavailable", http.StatusInternalServerError)
+				return
+			}
+			u.wait()
+			w.Write(upVideoPage(query.Get("mid"), query.Get("pn"), query.Get("ps"), u))
+		case "/x/web-interface/archive/stat":
+			u.mu.Lock()
+			u.stats++
+			u.mu.Unlock()
+			if u.statFail {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			aid, _ := strconv.ParseInt(strings.TrimPrefix(query.Get("bvid"), "BV"), 10, 64)
+			fmt.Fprintf(w, `{"code":0,"data":{"aid":%d,"view":%d,"danmaku":1,"reply":2,"favorite":%d,"coin":%d,"like":%d}}`,
+				aid, aid*100, aid*3, aid*2, aid)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(u.Close)
+
+	return u
+}
+
+func (u *videoUpstream) enter() {
+	u.mu.Lock()
+	u.active++
+	if u.active > u.peak {
+		u.peak = u.active
+	}
+	u.mu.Unlock()
+
+	time.Sleep(u.delay)
+}
+
+func (u *videoUpstream) leave() {
+	u.mu.Lock()
+	u.active--
+	u.mu.Unlock()
+}
+
+// wait 等待并发请求数达到 barrier，最多等待 2 秒
+func (u *videoUpstream) wait() {
+	u.mu.Lock()
+	if u.barrier <= 0 {
+		u.mu.Unlock()
+		return
+	}
+	u.waiting++
+	if u.waiting == u.barrier {
+		close(u.release)
+	}
+	u.mu.Unlock()
+
+	select {
+	case <-u.release:
+	case <-time.After(2 * time.Second):
+	}
+}
+
+func upVideoPage(mid, pn, ps string, u *videoUpstream) []byte {
+	u.mu.Lock()
+	u.pages = append(u.pages, mid+":"+pn+":"+ps)
+	u.mu.Unlock()
+
+	videos := upVideos[mid]
+	if mid == "many" {
+		videos = nil
+		for i := 0; i < 120; i++ {
+			videos = append(videos, upVideo{aid: int64(1000 + i), created: int64(1700000000 - i), play: "1"})
+		}
+	}
+
+	page, _ := strconv.Atoi(pn)
+	size, _ := strconv.Atoi(ps)
+	start, end := (page-1)*size, page*size
+	if start > len(videos) {
+		start = len(videos)
+	}
+	if end > len(videos) {
+		end = len(videos)
+	}
+
+	items := make([]string, 0, end-start)
+	for _, video := range videos[start:end] {
+		items = append(items, fmt.Sprintf(`{"aid":%d,"bvid":"BV%d","title":"视频%d","created":%d,"play":%s,"comment":3,"video_review":4,"length":"01:00"}`,
+			video.aid, video.aid, video.aid, video.created, video.play))
+	}
+	return []byte(fmt.Sprintf(`{"code":0,"data":{"list":{"vlist":[%s]},"page":{"pn":%d,"ps":%d,"count":%d}}}`,
+		strings.Join(items, ","), page, size, len(videos)))
+}
+
+// videoWidgetData 使用指向 upstream 的服务管理器获取组件中的视频
+func videoWidgetData(t *testing.T, upstream *videoUpstream, w *widget.BilibiliVideosWidget) []widget.BilibiliVideoData {
+	t.Helper()
+
+	return videoWidgetResult(t, upstream, w)["videos"].([]widget.BilibiliVideoData)
+}
+
+// videoWidgetResult 使用指向 upstream 的服务管理器获取组件数据
+func videoWidgetResult(t *testing.T, upstream *videoUpstream, w *widget.BilibiliVideosWidget) map[string]interface{} {
+	t.Helper()
+
+	sm := service.NewServiceManager(&service.Config{
+		APISources: map[string]service.APISourceConfig{
+			"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+		},
+		RateLimit:   service.RateLimitConfig{DefaultLimit: 1000000},
+		Performance: service.PerformanceConfig{MaxWorkers: 16},
+	})
+	ctx := context.WithValue(context.Background(), "serviceManager", sm)
+	ctx = context.WithValue(ctx, "locale", "zh-CN")
+
+	data, err := w.GetData(ctx, nil)
+	if err != nil {
+		t.Fatalf("获取数据失败: %v", err)
+	}
+	return data.(map[string]interface{})
+}
+
+func videoTitles(videos []widget.BilibiliVideoData) string {
+	titles := make([]string, 0, len(videos))
+	for _, video := range videos {
+		titles = append(titles, video.Title)
+	}
+	return strings.Join(titles, ",")
+}
+
+func twoUPWidget(sort string) *widget.BilibiliVideosWidget {
+	w := widget.NewBilibiliVideosWidget()
+	w.UPMasters = []widget.BilibiliUPMaster{{UID: "1", Name: "甲"}, {UID: "2", Name: "乙"}}
+	w.Sort = sort
+	return w
+}
+
+// TestBilibiliWidgetSortModes 多个UP主的视频按发布时间、播放量或轮流合并
+func TestBilibiliWidgetSortModes(t *testing.T) {
+	upstream := newVideoUpstream(t)
+	upstream.statFail = true // 按视频列表中的播放量排序
+
+	cases := map[string]string{
+		"newest":      "视频11,视频21,视频12,视频22",
+		"views":       "视频21,视频12,视频11,视频22",
+		"round-robin": "视频11,视频21,视频12,视频22",
+	}
+	for sort, want := range cases {
+		if got := videoTitles(videoWidgetData(t, upstream, twoUPWidget(sort))); got != want {
+			t.Errorf("sort=%s: got %s, want %s", sort, got, want)
+		}
+	}
+
+	// 按播放量排序时使用统计接口返回的最新播放量
+	upstream.statFail = false
+	if got := videoTitles(videoWidgetData(t, upstream, twoUPWidget("views"))); got != "视频22,视频21,视频12,视频11" {
+		t.Errorf("应按统计数据中的播放量排序: %s", got)
+	}
+
+	// 轮流合并时第一个UP主不会占满 limit
+	w := twoUPWidget("round-robin")
+	w.Limit = 2
+	if got := videoTitles(videoWidgetData(t, upstream, w)); got != "视频11,视频21" {
+		t.Errorf("round-robin 应轮流取各UP主的视频: %s", got)
+	}
+}
+
+// TestBilibiliWidgetPerUPLimit per-up-limit 限制每个UP主的视频数，UP主自身的 limit 优先
+func TestBilibiliWidgetPerUPLimit(t *testing.T) {
+	upstream := newVideoUpstream(t)
+
+	w := twoUPWidget("newest")
+	w.PerUPLimit = 1
+	w.UPMasters[1].Limit = 2
+	if got := videoTitles(videoWidgetData(t, upstream, w)); got != "视频11,视频21,视频22" {
+		t.Errorf("UP主视频数上限错误: %s", got)
+	}
+}
+
+// TestBilibiliWidgetStatsAndURLs 视频带 BV 号链接以及播放、点赞、投币、收藏数
+func TestBilibiliWidgetStatsAndURLs(t *testing.T) {
+	upstream := newVideoUpstream(t)
+	videos := videoWidgetData(t, upstream, twoUPWidget("newest"))
+
+	video := videos[0]
+	if video.BVID != "BV11" || video.VideoURL != "https://www.bilibili.com/video/BV11" {
+		t.Errorf("应使用 BV 号链接: %+v", video)
+	}
+	if video.ViewCount != 1100 || video.LikeCount != 11 || video.CoinCount != 22 || video.FavoriteCount != 33 {
+		t.Errorf("统计数据错误: %+v", video)
+	}
+	if video.LikeCountFormatted == "" || video.FavoriteCountFormatted == "" {
+		t.Errorf("缺少格式化的统计数据: %+v", video)
+	}
+
+	// 统计接口不可用时保留视频列表中的播放量，隐藏的播放量按 0 处理
+	upstream.statFail = true
+	videos = videoWidgetData(t, upstream, twoUPWidget("newest"))
+	if videos[0].ViewCount != 10 || videos[0].DanmakuCount != 4 || videos[3].ViewCount != 0 {
+		t.Errorf("应使用视频列表中的数据: %+v", videos)
+	}
+}
+
+// TestBilibiliWidgetReportsFailedUPMasters 个别UP主获取失败时返回其余视频，并提示失败的UP主
+func TestBilibiliWidgetReportsFailedUPMasters(t *testing.T) {
+	upstream := newVideoUpstream(t)
+
+	w := twoUPWidget("newest")
+	w.UPMasters[1] = widget.BilibiliUPMaster{UID: "broken", Name: "丙"}
+	data := videoWidgetResult(t, upstream, w)
+
+	if got := videoTitles(data["videos"].([]widget.BilibiliVideoData)); got != "视频11,视频12" {
+		t.Errorf("应返回其余UP主的视频: %s", got)
+	}
+	if partial, _ := data["partial"].(string); !strings.Contains(partial, "丙") {
+		t.Errorf("应提示获取失败的UP主: %q", partial)
+	}
+}
+
+// TestBilibiliWidgetStatsLimit 只为前 stats-limit 个视频请求统计接口，-1 时不请求
+func TestBilibiliWidgetStatsLimit(t *testing.T) {
+	upstream := newVideoUpstream(t)
+
+	w := twoUPWidget("newest")
+	w.StatsLimit = 1
+	videos := videoWidgetData(t, upstream, w)
+	if videos[0].LikeCount != 11 || videos[1].LikeCount != 0 || videos[1].ViewCount != 1000 {
+		t.Errorf("只有第一个视频应获取统计数据: %+v", videos)
+	}
+	if !videos[0].HasStats || videos[1].HasStats {
+		t.Errorf("只有获取了统计数据的视频应标记 HasStats: %+v", videos)
+	}
+
+	w.StatsLimit = -1
+	videoWidgetData(t, upstream, w)
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	if upstream.stats != 1 {
+		t.Errorf("统计接口应只请求 1 次, got %d", upstream.stats)
+	}
+}
+
+// TestBilibiliWidgetRendersStatsOnlyWhenFetched 超出 stats-limit 的视频不渲染点赞、投币、收藏行
+func TestBilibiliWidgetRendersStatsOnlyWhenFetched(t *testing.T) {
+	upstream := newVideoUpstream(t)
+	ts := newTestServer(t, `
+server:
+  api-sources:
+    bilibili:
+      base-url: `+upstream.URL+`
+pages:
+  - name: 首页
+    columns:
+      - size: full
+        widgets:
+          - type: bilibili-videos
+            stats-limit: 1
+            up-masters:
+              - uid: "1"
+              - uid: "2"
+`)
+
+	status, body := getPage(t, ts.URL+"/")
+	if status != http.StatusOK || strings.Count(body, `class="card"`) != 4 {
+		t.Fatalf("应渲染 4 个视频: %d\n%s", status, body)
+	}
+	if got := strings.Count(body, "点赞"); got != 1 {
+		t.Errorf("只有获取了统计数据的视频应渲染点赞行, 渲染了 %d 次", got)
+	}
+}
+
+// TestBilibiliWidgetFetchesConcurrentlyAndPaginates 并发获取各UP主的视频，超过一页时翻页
+func TestBilibiliWidgetFetchesConcurrentlyAndPaginates(t *testing.T) {
+	upstream := newVideoUpstream(t)
+	upstream.barrier = 2
+	upstream.statFail = true
+
+	w := widget.NewBilibiliVideosWidget()
+	w.UPMasters = []widget.BilibiliUPMaster{{UID: "1", Name: "甲"}, {UID: "many", Name: "多"}}
+	w.Limit = 60
+
+	start := time.Now()
+	videos := videoWidgetData(t, upstream, w)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("各UP主应并发获取, 耗时 %v", elapsed)
+	}
+	if len(videos) != 60 {
+		t.Fatalf("应返回 limit 个视频, got %d", len(videos))
+	}
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	pages := strings.Join(upstream.pages, " ")
+	if !strings.Contains(pages, "many:1:50") || !strings.Contains(pages, "many:2:50") || strings.Contains(pages, "many:3") {
+		t.Errorf("应读取两页: %s", pages)
+	}
+}
+
+// TestBilibiliWidgetBoundsConcurrency 同时请求上游的数量不超过 concurrency
+func TestBilibiliWidgetBoundsConcurrency(t *testing.T) {
+	upstream := newVideoUpstream(t)
+	upstream.delay = 20 * time.Millisecond
+
+	w := widget.NewBilibiliVideosWidget()
+	for i := 0; i < 6; i++ {
+		w.UPMasters = append(w.UPMasters, widget.BilibiliUPMaster{UID: "up" + strconv.Itoa(i), Name: "UP"})
+	}
+	w.Concurrency = 2
+	videoWidgetData(t, upstream, w)
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	if upstream.peak > 2 {
+		t.Errorf("同时请求数不应超过 2, got %d", upstream.peak)
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestCircuitBreakerOpensAndRecovers 连续失败达到阈值后打开，冷却结束后由探测请求关闭
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Status: http.StatusBadGateway})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"weibo": {
+			BaseURL:        upstream.URL,
+			Timeout:        5 * time.Second,
+			CircuitBreaker: service.CircuitBreakerConfig{FailureThreshold: 3, CoolDown: 100 * time.Millisecond},
+		},
+	}, service.RateLimitConfig{})
+
+	for i := 0; i < 3; i++ {
+		if err := getX(sm, "weibo"); err == nil {
+			t.Fatal("502 应返回错误")
+		}
+	}
+	if state := sm.CircuitBreakers()["weibo"].State; state != service.BreakerOpen {
+		t.Fatalf("连续失败 3 次后应打开, got %s", state)
+	}
+
+	// 打开状态下不再请求上游
+	if err := getX(sm, "weibo"); !errors.Is(err, service.ErrCircuitOpen) {
+		t.Errorf("应返回 ErrCircuitOpen, got %v", err)
+	}
+	if got := upstream.Hits(); got != 3 {
+		t.Errorf("打开状态下不应请求上游, got %d", got)
+	}
+	if sm.GetMetrics().CircuitBreakers["weibo"] != "open" {
+		t.Errorf("指标中应包含熔断状态: %v", sm.GetMetrics().CircuitBreakers)
+	}
+
+	// 冷却结束后探测请求失败，重新打开
+	time.Sleep(150 * time.Millisecond)
+	if err := getX(sm, "weibo"); errors.Is(err, service.ErrCircuitOpen) {
+		t.Fatal("冷却结束后应放行探测请求")
+	}
+	if state := sm.CircuitBreakers()["weibo"].State; state != service.BreakerOpen {
+		t.Fatalf("探测失败后应重新打开, got %s", state)
+	}
+
+	// 上游恢复后探测成功，关闭
+	upstream.SetStatus(http.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	if err := getX(sm, "weibo"); err != nil {
+		t.Fatalf("探测请求应成功: %v", err)
+	}
+	if state := sm.CircuitBreakers()["weibo"].State; state != service.BreakerClosed {
+		t.Errorf("探测成功后应关闭, got %s", state)
+	}
+}
+
+// TestCircuitBreakerSkipsToFallback 熔断打开时直接使用备用服务
+func TestCircuitBreakerSkipsToFallback(t *testing.T) {
+	primary := newUpstream(t, upstreamConfig{Status: http.StatusServiceUnavailable})
+	fallback := newUpstream(t, upstreamConfig{})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"weibo": {
+			BaseURL:        primary.URL,
+			Timeout:        5 * time.Second,
+			Fallbacks:      []string{"douyu"},
+			CircuitBreaker: service.CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute},
+		},
+		"douyu": {BaseURL: fallback.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{})
+
+	for i := 0; i < 3; i++ {
+		if err := getX(sm, "weibo"); err != nil {
+			t.Fatalf("应使用备用服务: %v", err)
+		}
+	}
+	if got := primary.Hits(); got != 1 {
+		t.Errorf("熔断后不应再请求主服务, got %d", got)
+	}
+	if got := fallback.Hits(); got != 3 {
+		t.Errorf("备用服务应请求 3 次, got %d", got)
+	}
+}
+
+// TestCircuitBreakerIgnoresClientErrors 404 等请求错误不计入失败
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Status: http.StatusNotFound})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"weibo": {
+			BaseURL:        upstream.URL,
+			Timeout:        5 * time.Second,
+			CircuitBreaker: service.CircuitBreakerConfig{FailureThreshold: 2},
+		},
+	}, service.RateLimitConfig{})
+
+	for i := 0; i < 5; i++ {
+		getX(sm, "weibo")
+	}
+	if got := upstream.Hits(); got != 5 {
+		t.Errorf("404 不应触发熔断, got %d", got)
+	}
+	if state := sm.CircuitBreakers()["weibo"].State; state != service.BreakerClosed {
+		t.Errorf("熔断器应保持关闭, got %s", state)
+	}
+}
+
+// TestCircuitBreakerClientErrorsKeepFailures 夹在失败之间的 404 不清零连续失败次数
+func TestCircuitBreakerClientErrorsKeepFailures(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Status: http.StatusBadGateway})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"weibo": {
+			BaseURL:        upstream.URL,
+			Timeout:        5 * time.Second,
+			CircuitBreaker: service.CircuitBreakerConfig{FailureThreshold: 2, CoolDown: time.Minute},
+		},
+	}, service.RateLimitConfig{})
+
+	getX(sm, "weibo")
+	upstream.SetStatus(http.StatusNotFound)
+	getX(sm, "weibo")
+	if got := sm.CircuitBreakers()["weibo"].Failures; got != 1 {
+		t.Errorf("404 不应清零失败次数, got %d", got)
+	}
+
+	upstream.SetStatus(http.StatusBadGateway)
+	getX(sm, "weibo")
+	if state := sm.CircuitBreakers()["weibo"].State; state != service.BreakerOpen {
+		t.Errorf("连续 2 次失败后应打开, got %s", state)
+	}
+}
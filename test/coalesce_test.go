@@ -0,0 +1,179 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestCoalescerSharesResult 并发的相同调用只执行一次，其余调用方共享结果
+func TestCoalescerSharesResult(t *testing.T) {
+	c := service.NewCoalescer()
+
+	var calls int32
+	fn := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "v1", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, _, err := c.Do(context.Background(), "key", fn); err != nil || value != "v1" {
+				t.Errorf("结果错误: %v (%v)", err, value)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("应只执行一次, 共执行 %d 次", calls)
+	}
+	if c.Coalesced() != 9 {
+		t.Errorf("合并次数错误: %d", c.Coalesced())
+	}
+
+	// 调用完成后不再共享结果
+	c.Do(context.Background(), "key", fn)
+	if calls != 2 {
+		t.Errorf("调用完成后应重新执行, 共执行 %d 次", calls)
+	}
+}
+
+// TestCoalescerLeaderCanceled 发起方被取消时，其他等待方重新发起调用
+func TestCoalescerLeaderCanceled(t *testing.T) {
+	c := service.NewCoalescer()
+
+	started := make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	go c.Do(leaderCtx, "key", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	value, _, err := c.Do(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		return "v1", nil
+	})
+	if err != nil || value != "v1" {
+		t.Errorf("等待方应重新发起调用: %v (%v)", err, value)
+	}
+}
+
+// TestStaleCacheCoalescesMisses 同一 key 的并发未命中只回源一次
+func TestStaleCacheCoalescesMisses(t *testing.T) {
+	cache := service.NewStaleCache(newTestMemoryCache(t, service.CacheConfig{}), 0)
+	loader := &countingLoader{value: "v1", delay: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var got string
+			if _, err := cache.Fetch(context.Background(), "key", time.Minute, &got, loader.load); err != nil || got != "v1" {
+				t.Errorf("读取失败: %v (%q)", err, got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if loader.count() != 1 {
+		t.Errorf("应只回源一次, 共调用 %d 次", loader.count())
+	}
+	if cache.Coalesced() != 4 {
+		t.Errorf("合并次数错误: %d", cache.Coalesced())
+	}
+}
+
+// TestStaleCacheCoalescedError 合并的调用方共享上游错误
+func TestStaleCacheCoalescedError(t *testing.T) {
+	cache := service.NewStaleCache(newTestMemoryCache(t, service.CacheConfig{}), 0)
+	upstream := errors.New("rate limited")
+	loader := &countingLoader{err: upstream, delay: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var got string
+			if _, err := cache.Fetch(context.Background(), "key", time.Minute, &got, loader.load); !errors.Is(err, upstream) {
+				t.Errorf("应返回上游错误, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if loader.count() != 1 {
+		t.Errorf("应只回源一次, 共调用 %d 次", loader.count())
+	}
+}
+
+// TestServiceManagerCoalescesRequests 相同的并发 API 请求共享一次上游调用
+func TestServiceManagerCoalescesRequests(t *testing.T) {
+	var hits int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer upstream.Close()
+
+	sm := service.NewServiceManager(&service.Config{
+		APISources: map[string]service.APISourceConfig{
+			"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := sm.RequestWithFallback(context.Background(), "bilibili", &service.APIRequest{
+				Method: "GET",
+				Path:   "/x/space/arc/search",
+				Params: map[string]interface{}{"mid": "1", "ps": 10},
+			})
+			if err != nil || resp == nil || string(resp.Body) != `{"code":0}` {
+				t.Errorf("请求失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := sm.GetMetrics().CoalescedRequests; got != 4 {
+		t.Errorf("合并请求数错误: %d", got)
+	}
+
+	// 参数不同的请求不合并
+	before := atomic.LoadInt32(&hits)
+	sm.RequestWithFallback(context.Background(), "bilibili", &service.APIRequest{
+		Method: "GET",
+		Path:   "/x/space/arc/search",
+		Params: map[string]interface{}{"mid": "2", "ps": 10},
+	})
+	if atomic.LoadInt32(&hits) == before {
+		t.Error("不同请求不应共享结果")
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linzi007/glance-china/internal/config"
+)
+
+// writeConfigFile 在 dir 中写入配置文件并返回其路径
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("写入 %s 失败: %v", name, err)
+	}
+	return path
+}
+
+// TestConfigExpandsEnv 配置值中的环境变量引用按 shell 规则展开
+func TestConfigExpandsEnv(t *testing.T) {
+	t.Setenv("GLANCE_TEST_HOST", "example.com")
+	t.Setenv("GLANCE_TEST_EMPTY", "")
+	os.Unsetenv("GLANCE_TEST_UNSET")
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`${GLANCE_TEST_HOST}`, "example.com"},
+		{`api.${GLANCE_TEST_HOST}:8080`, "api.example.com:8080"},
+		{`${GLANCE_TEST_UNSET}`, ""},
+		{`${GLANCE_TEST_UNSET:-localhost}`, "localhost"},
+		{`${GLANCE_TEST_EMPTY:-localhost}`, "localhost"},
+		{`${GLANCE_TEST_HOST:-localhost}`, "example.com"},
+		{`${GLANCE_TEST_UNSET-localhost}`, "localhost"},
+		{`${GLANCE_TEST_EMPTY-localhost}`, ""},
+		{`${GLANCE_TEST_HOST:?host is required}`, "example.com"},
+		{`${GLANCE_TEST_EMPTY?host is required}`, ""},
+		{`$${GLANCE_TEST_HOST}`, "${GLANCE_TEST_HOST}"},
+		{`'${GLANCE_TEST_HOST}'`, "example.com"},
+	}
+
+	for _, tt := range tests {
+		path := writeConfigFile(t, t.TempDir(), "glance.yml", "server:\n  host: "+tt.value+"\n")
+		cfg, err := config.LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s: 加载配置失败: %v", tt.value, err)
+			continue
+		}
+		if cfg.Server.Host != tt.want {
+			t.Errorf("%s: 应展开为 %q, got %q", tt.value, tt.want, cfg.Server.Host)
+		}
+	}
+}
+
+// TestConfigEnvKeepsTypes 未加引号的值按展开后的内容推断类型
+func TestConfigEnvKeepsTypes(t *testing.T) {
+	t.Setenv("GLANCE_TEST_PORT", "9090")
+
+	path := writeConfigFile(t, t.TempDir(), "glance.yml", "server:\n  port: ${GLANCE_TEST_PORT}\n  refresh:\n    enabled: ${GLANCE_TEST_REFRESH:-true}\n")
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("port 应解析为 9090, got %d", cfg.Server.Port)
+	}
+	if !cfg.Server.Refresh.Enabled {
+		t.Error("enabled 应解析为 true")
+	}
+}
+
+// TestConfigReadsFileTag !file 读取文件内容作为配置值，相对路径基于配置文件所在目录
+func TestConfigReadsFileTag(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "gitee_token", "secret-token\n")
+	t.Setenv("GLANCE_TEST_SECRET", "gitee_token")
+
+	tests := []string{
+		`!file gitee_token`,
+		`!file ` + filepath.Join(dir, "gitee_token"),
+		`!file ${GLANCE_TEST_SECRET}`,
+	}
+
+	for _, value := range tests {
+		path := writeConfigFile(t, dir, "glance.yml", "server:\n  api-sources:\n    gitee:\n      token: "+value+"\n")
+		cfg, err := config.LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s: 加载配置失败: %v", value, err)
+			continue
+		}
+		if got := cfg.Server.APISources["gitee"].Token; got != "secret-token" {
+			t.Errorf("%s: 应读取文件内容并去掉末尾换行, got %q", value, got)
+		}
+	}
+}
+
+// TestConfigEnvErrors 无法展开的引用返回带文件名和行号的错误
+func TestConfigEnvErrors(t *testing.T) {
+	t.Setenv("GLANCE_TEST_EMPTY", "")
+	os.Unsetenv("GLANCE_TEST_UNSET")
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`${GLANCE_TEST_UNSET:?gitee token is required}`, "GLANCE_TEST_UNSET: gitee token is required"},
+		{`${GLANCE_TEST_EMPTY:?gitee token is required}`, "GLANCE_TEST_EMPTY: gitee token is required"},
+		{`${GLANCE_TEST_UNSET?}`, "GLANCE_TEST_UNSET: required environment variable is not set"},
+		{`${GLANCE_TEST_UNSET`, "unterminated variable reference"},
+		{`${}`, "invalid variable reference"},
+		{`!file missing_token`, "failed to read missing_token"},
+	}
+
+	for _, tt := range tests {
+		path := writeConfigFile(t, t.TempDir(), "glance.yml", "server:\n  api-sources:\n    gitee:\n      token: "+tt.value+"\n")
+		_, err := config.LoadConfig(path)
+		if err == nil {
+			t.Errorf("%s: 应返回错误", tt.value)
+			continue
+		}
+		for _, want := range []string{path, "line 4", tt.want} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: 错误应包含 %q, got %v", tt.value, want, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+const testLayoutConfig = `
+theme:
+  background-color: 240 8 9
+pages:
+  - name: My Page
+    columns:
+      - size: small
+        widgets:
+          - type: bilibili-videos
+            title: 关注的UP主
+            limit: 5
+            up-masters:
+              - uid: "123"
+                name: 老番茄
+                limit: 2
+          - type: zhihu-trending
+      - size: full
+        widgets:
+          - type: custom-widget
+            url: https://example.com
+`
+
+// TestConfigDecodesWidgetsByType 组件按 type 解析为注册的具体类型，未配置的字段保留组件默认值
+func TestConfigDecodesWidgetsByType(t *testing.T) {
+	path := writeConfigFile(t, t.TempDir(), "glance.yml", testLayoutConfig)
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if got := cfg.Theme.BackgroundColor.String(); got != "hsl(240 8% 9%)" {
+		t.Errorf("主题颜色解析错误: %s", got)
+	}
+	if len(cfg.Pages) != 1 || cfg.Pages[0].GetSlug() != "my-page" {
+		t.Fatalf("页面解析错误: %+v", cfg.Pages)
+	}
+	columns := cfg.Pages[0].Columns
+	if len(columns) != 2 || columns[0].Size != "small" || len(columns[0].Widgets) != 2 {
+		t.Fatalf("列解析错误: %+v", columns)
+	}
+
+	videos, ok := columns[0].Widgets[0].Widget.(*widget.BilibiliVideosWidget)
+	if !ok {
+		t.Fatalf("bilibili-videos 应解析为 *widget.BilibiliVideosWidget, got %T", columns[0].Widgets[0].Widget)
+	}
+	if videos.Title != "关注的UP主" || videos.Limit != 5 {
+		t.Errorf("配置的字段解析错误: title %q, limit %d", videos.Title, videos.Limit)
+	}
+	if want := []widget.BilibiliUPMaster{{UID: "123", Name: "老番茄", Limit: 2}}; !reflect.DeepEqual(videos.UPMasters, want) {
+		t.Errorf("up-masters 解析错误: %+v", videos.UPMasters)
+	}
+	if videos.Sort != "newest" || videos.Style != "horizontal-cards" || videos.CollapseAfter != 5 || videos.APISource != "bilibili" {
+		t.Errorf("未配置的字段应保留默认值: %+v", videos)
+	}
+	if columns[0].Widgets[0].Line != 9 {
+		t.Errorf("应记录组件所在行, got %d", columns[0].Widgets[0].Line)
+	}
+
+	trending, ok := columns[0].Widgets[1].Widget.(*widget.ZhihuTrendingWidget)
+	if !ok {
+		t.Fatalf("zhihu-trending 应解析为 *widget.ZhihuTrendingWidget, got %T", columns[0].Widgets[1].Widget)
+	}
+	if trending.Limit != 15 || !reflect.DeepEqual(trending.Categories, []string{"all"}) {
+		t.Errorf("未配置的字段应保留默认值: %+v", trending)
+	}
+
+	unknown := columns[1].Widgets[0]
+	if unknown.Type != "custom-widget" || unknown.Widget != nil || unknown.Line != 19 {
+		t.Errorf("未注册的组件应保留类型和行号, 不创建实例: %+v", unknown)
+	}
+}
+
+// TestConfigWidgetErrors 缺少 type 或字段类型错误时返回带行号的错误
+func TestConfigWidgetErrors(t *testing.T) {
+	tests := []struct {
+		widget string
+		want   string
+	}{
+		{"title: 无类型", "line 6: widget has no type"},
+		{"type: bilibili-videos\n            limit: ten", "line 6: invalid bilibili-videos widget"},
+		{"type: zhihu-trending\n            categories: all", "line 6: invalid zhihu-trending widget"},
+		{"type: zhihu-trending\n            cache: soon", `line 6: invalid cache duration "soon"`},
+		{"type: zhihu-trending\n            cache: -1m", `line 6: invalid cache duration "-1m"`},
+	}
+
+	for _, tt := range tests {
+		content := "pages:\n  - name: 首页\n    columns:\n      - widgets:\n          - " + tt.widget + "\n"
+		path := writeConfigFile(t, t.TempDir(), "glance.yml", "\n"+content)
+		_, err := config.LoadConfig(path)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: 错误应包含 %q, got %v", tt.widget, tt.want, err)
+		}
+	}
+}
+
+// TestConfigWidgetCacheDuration 组件的 cache 解析为缓存时长，未配置时使用默认的 5 分钟
+func TestConfigWidgetCacheDuration(t *testing.T) {
+	content := "pages:\n  - name: 首页\n    columns:\n      - widgets:\n          - type: zhihu-trending\n            cache: 1h\n          - type: bilibili-videos\n            cache: 30s\n          - type: gitee-repos\n"
+	path := writeConfigFile(t, t.TempDir(), "glance.yml", content)
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	want := []time.Duration{time.Hour, 30 * time.Second, 5 * time.Minute}
+	for i, w := range cfg.Pages[0].Columns[0].Widgets {
+		if got := w.Widget.GetCacheDuration(); got != want[i] {
+			t.Errorf("%s: 缓存时长应为 %v, got %v", w.Type, want[i], got)
+		}
+	}
+}
+
+// TestConfigIncludesLayout !include 引入的页面和组件列表按所在位置解析
+func TestConfigIncludesLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "widgets.yml", "- type: douyu-live\n- type: gitee-repos\n")
+	writeConfigFile(t, dir, "pages.yml", "- name: 直播\n  columns:\n    - widgets: !include widgets.yml\n")
+	path := writeConfigFile(t, dir, "glance.yml", "pages: !include pages.yml\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if len(cfg.Pages) != 1 || len(cfg.Pages[0].Columns) != 1 {
+		t.Fatalf("引入的页面解析错误: %+v", cfg.Pages)
+	}
+	widgets := cfg.Pages[0].Columns[0].Widgets
+	if len(widgets) != 2 {
+		t.Fatalf("引入的组件列表解析错误: %+v", widgets)
+	}
+	if _, ok := widgets[0].Widget.(*widget.DouyuLiveWidget); !ok {
+		t.Errorf("douyu-live 应解析为 *widget.DouyuLiveWidget, got %T", widgets[0].Widget)
+	}
+	if _, ok := widgets[1].Widget.(*widget.GiteeReposWidget); !ok {
+		t.Errorf("gitee-repos 应解析为 *widget.GiteeReposWidget, got %T", widgets[1].Widget)
+	}
+
+	writeConfigFile(t, dir, "loop.yml", "pages: !include loop.yml\n")
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"\npages: !include missing.yml\n", "line 2"},
+		{"pages: !include loop.yml\n", "include depth exceeds"},
+	}
+	for _, tt := range tests {
+		path := writeConfigFile(t, dir, "glance.yml", tt.content)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: 错误应包含 %q, got %v", tt.content, tt.want, err)
+		}
+	}
+}
+
+// TestConfigDefaults 未配置的服务端口和语言设置使用默认值
+func TestConfigDefaults(t *testing.T) {
+	path := writeConfigFile(t, t.TempDir(), "glance.yml", "pages: []\n")
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if cfg.Server.Port != 8080 {
+		t.Errorf("默认端口应为 8080, got %d", cfg.Server.Port)
+	}
+	locale := cfg.Locale
+	if locale.Default != "zh-CN" || !reflect.DeepEqual(locale.Supported, []string{"zh-CN", "en-US"}) || locale.TimeZone != "Asia/Shanghai" {
+		t.Errorf("默认语言设置错误: %+v", locale)
+	}
+	if !locale.NumberFormat.UseChineseUnits {
+		t.Error("默认中文环境应使用中文数字单位")
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Errorf("默认配置应通过校验: %v", err)
+	}
+
+	path = writeConfigFile(t, t.TempDir(), "glance.yml", "server:\n  port: 9000\nlocale:\n  default: en-US\n")
+	if cfg, err = config.LoadConfig(path); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Locale.NumberFormat.UseChineseUnits {
+		t.Errorf("配置的值不应被默认值覆盖: port %d, use-chinese-units %v", cfg.Server.Port, cfg.Locale.NumberFormat.UseChineseUnits)
+	}
+}
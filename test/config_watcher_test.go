@@ -0,0 +1,155 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/config"
+)
+
+// logBuffer 可并发写入的日志输出，用于检查监听器记录的日志
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLog 将标准日志输出重定向到缓冲区，测试结束后恢复
+func captureLog(t *testing.T) *logBuffer {
+	t.Helper()
+
+	logs := &logBuffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return logs
+}
+
+// startWatcher 创建并启动监听器，返回接收重新加载后配置的通道
+func startWatcher(t *testing.T, path string, interval time.Duration) (*config.Watcher, <-chan *config.AppConfig) {
+	t.Helper()
+
+	watcher, err := config.NewWatcher(path, interval)
+	if err != nil {
+		t.Fatalf("创建监听器失败: %v", err)
+	}
+
+	reloads := make(chan *config.AppConfig, 16)
+	watcher.OnReload(func(cfg *config.AppConfig) { reloads <- cfg })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go watcher.Start(ctx)
+
+	return watcher, reloads
+}
+
+func waitReload(t *testing.T, reloads <-chan *config.AppConfig) *config.AppConfig {
+	t.Helper()
+
+	select {
+	case cfg := <-reloads:
+		return cfg
+	case <-time.After(2 * time.Second):
+		t.Fatal("配置未重新加载")
+		return nil
+	}
+}
+
+// TestWatcherReloadsOnWrite 配置文件和 !include 引用的文件修改后重新加载，并通知回调
+func TestWatcherReloadsOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "pages.yml", "- name: 首页\n")
+	path := writeConfigFile(t, dir, "glance.yml", "server:\n  host: before\npages: !include pages.yml\n")
+	watcher, reloads := startWatcher(t, path, 20*time.Millisecond)
+
+	writeConfigFile(t, dir, "glance.yml", "server:\n  host: after\npages: !include pages.yml\n")
+	if cfg := waitReload(t, reloads); cfg.Server.Host != "after" {
+		t.Errorf("回调应收到新配置, got host %q", cfg.Server.Host)
+	}
+	if got := watcher.Config().Server.Host; got != "after" {
+		t.Errorf("当前配置应更新, got host %q", got)
+	}
+
+	writeConfigFile(t, dir, "pages.yml", "- name: 首页\n- name: 技术\n")
+	if cfg := waitReload(t, reloads); len(cfg.Pages) != 2 {
+		t.Errorf("修改引入的文件后应重新加载, got %d 个页面", len(cfg.Pages))
+	}
+}
+
+// TestWatcherDebouncesWrites 连续写入期间不重新加载，写入结束后只加载一次最终内容
+func TestWatcherDebouncesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfigFile(t, dir, "glance.yml", "server:\n  port: 8000\n")
+	_, reloads := startWatcher(t, path, 100*time.Millisecond)
+
+	for port := 8001; port <= 8040; port++ {
+		writeConfigFile(t, dir, "glance.yml", "server:\n  port: "+strconv.Itoa(port)+"\n")
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(reloads) != 0 {
+		t.Fatalf("连续写入期间不应重新加载, 已加载 %d 次", len(reloads))
+	}
+
+	if cfg := waitReload(t, reloads); cfg.Server.Port != 8040 {
+		t.Errorf("应加载最后一次写入的内容, got port %d", cfg.Server.Port)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if len(reloads) != 0 {
+		t.Errorf("写入结束后应只加载一次, 多加载了 %d 次", len(reloads))
+	}
+}
+
+// TestWatcherKeepsConfigOnInvalidChange 新配置解析或校验失败时保留当前配置，并记录被拒绝的改动
+func TestWatcherKeepsConfigOnInvalidChange(t *testing.T) {
+	logs := captureLog(t)
+
+	dir := t.TempDir()
+	path := writeConfigFile(t, dir, "glance.yml", "server:\n  host: good\n")
+	watcher, reloads := startWatcher(t, path, 20*time.Millisecond)
+
+	invalid := []string{
+		"server:\n  host: [unclosed\n",
+		"server:\n  host: good\nlocale:\n  default: fr-FR\n",
+	}
+	for _, content := range invalid {
+		before := strings.Count(logs.String(), "config reload failed")
+		writeConfigFile(t, dir, "glance.yml", content)
+		waitFor(t, func() bool { return strings.Count(logs.String(), "config reload failed") > before }, "无效配置应记录加载失败: "+content)
+		if len(reloads) != 0 {
+			t.Errorf("无效配置不应通知回调: %q", content)
+		}
+		if got := watcher.Config().Server.Host; got != "good" {
+			t.Errorf("应保留当前配置, got host %q", got)
+		}
+	}
+
+	output := logs.String()
+	for _, want := range []string{"rejected changes in " + path, "2: -   host: good", "2: +   host: [unclosed", "3: + locale:"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("日志应包含被拒绝的改动 %q:\n%s", want, output)
+		}
+	}
+
+	writeConfigFile(t, dir, "glance.yml", "server:\n  host: fixed\n")
+	if cfg := waitReload(t, reloads); cfg.Server.Host != "fixed" {
+		t.Errorf("修正后应重新加载, got host %q", cfg.Server.Host)
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+func newTestDiskCache(t *testing.T, config service.CacheConfig) *service.DiskCache {
+	t.Helper()
+
+	cache, err := service.NewDiskCache(config)
+	if err != nil {
+		t.Fatalf("创建磁盘缓存失败: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+
+	return cache
+}
+
+// TestDiskCacheSurvivesRestart 重新创建缓存后仍能读取之前写入的数据
+func TestDiskCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	want := map[string]interface{}{"title": "B站热门", "limit": float64(20)}
+	first := newTestDiskCache(t, service.CacheConfig{Dir: dir, TTL: time.Minute})
+	if err := first.Set(ctx, "widget:bilibili-videos", want, 0); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	first.Close()
+
+	second := newTestDiskCache(t, service.CacheConfig{Dir: dir, TTL: time.Minute})
+
+	var got map[string]interface{}
+	if err := second.Get(ctx, "widget:bilibili-videos", &got); err != nil {
+		t.Fatalf("重启后读取缓存失败: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("缓存内容不一致: got %v, want %v", got, want)
+	}
+	if second.Size() == 0 {
+		t.Error("重启后应恢复缓存大小统计")
+	}
+}
+
+// TestDiskCacheTTL 磁盘缓存过期测试
+func TestDiskCacheTTL(t *testing.T) {
+	cache := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "short", "value", 50*time.Millisecond); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	var got string
+	if err := cache.Get(ctx, "short", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("过期后应返回 ErrCacheMiss, got %v (%q)", err, got)
+	}
+	if cache.Size() != 0 {
+		t.Errorf("过期条目应被删除, size = %d", cache.Size())
+	}
+}
+
+// TestDiskCacheMaxSize 超出容量时淘汰最久未访问的条目
+func TestDiskCacheMaxSize(t *testing.T) {
+	ctx := context.Background()
+
+	// 按单条记录的实际大小设置容量，只能容纳三条
+	probe := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+	if err := probe.Set(ctx, "a", "a", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	maxSize := probe.Size()*3 + probe.Size()/2
+
+	cache := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir(), MaxSize: service.ByteSize(maxSize)})
+
+	var got string
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+			t.Fatalf("写入缓存失败: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 访问 a，使 b 成为最久未访问的条目
+	if err := cache.Get(ctx, "a", &got); err != nil {
+		t.Fatalf("读取缓存失败: %v", err)
+	}
+
+	if err := cache.Set(ctx, "d", "d", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	if cache.Size() > maxSize {
+		t.Errorf("缓存大小超出限制: %d", cache.Size())
+	}
+	if err := cache.Get(ctx, "b", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("b 应被淘汰, got %v", err)
+	}
+	for _, key := range []string{"a", "d"} {
+		if err := cache.Get(ctx, key, &got); err != nil {
+			t.Errorf("%s 不应被淘汰: %v", key, err)
+		}
+	}
+}
+
+// TestDiskCacheClearKeepsOtherFiles Clear 只删除缓存文件
+func TestDiskCacheClearKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "README")
+	if err := os.WriteFile(other, []byte("keep"), 0o644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+
+	cache := newTestDiskCache(t, service.CacheConfig{Dir: dir, TTL: time.Minute})
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(ctx, key, key, 0); err != nil {
+			t.Fatalf("写入缓存失败: %v", err)
+		}
+	}
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("清空缓存失败: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "README" {
+		t.Errorf("Clear 后目录内容错误: %v", entries)
+	}
+}
+
+// TestDiskCacheExpiredGetKeepsConcurrentSet 读取过期条目时并发写入的新值不会被删除
+func TestDiskCacheExpiredGetKeepsConcurrentSet(t *testing.T) {
+	cache := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		if err := cache.Set(ctx, "key", "old", time.Millisecond); err != nil {
+			t.Fatalf("写入缓存失败: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var value string
+			cache.Get(ctx, "key", &value)
+		}()
+		go func() {
+			defer wg.Done()
+			cache.Set(ctx, "key", "new", time.Minute)
+		}()
+		wg.Wait()
+
+		var value string
+		if err := cache.Get(ctx, "key", &value); err != nil || value != "new" {
+			t.Fatalf("第 %d 次: 并发写入的新值被删除: %q %v", i, value, err)
+		}
+	}
+}
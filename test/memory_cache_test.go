@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+func newTestMemoryCache(t *testing.T, config service.CacheConfig) *service.MemoryCache {
+	t.Helper()
+
+	cache := service.NewMemoryCache(config)
+	t.Cleanup(func() { cache.Close() })
+
+	return cache
+}
+
+// TestMemoryCacheLRU 超出容量时淘汰最久未访问的条目，而不是最早过期的条目
+func TestMemoryCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	// 每条 "k1" + "\"v\"" 为 5 字节
+	cache := newTestMemoryCache(t, service.CacheConfig{MaxSize: 15})
+
+	cache.Set(ctx, "k1", "v", time.Hour)
+	cache.Set(ctx, "k2", "v", time.Minute)
+	cache.Set(ctx, "k3", "v", time.Hour)
+
+	var got string
+	// 访问 k1，k2 成为最久未访问（同时也是最早过期）；再访问 k2，使 k3 成为淘汰对象
+	cache.Get(ctx, "k1", &got)
+	cache.Get(ctx, "k2", &got)
+
+	cache.Set(ctx, "k4", "v", time.Hour)
+
+	if err := cache.Get(ctx, "k3", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("k3 应被淘汰, got %v", err)
+	}
+	for _, key := range []string{"k1", "k2", "k4"} {
+		if err := cache.Get(ctx, key, &got); err != nil {
+			t.Errorf("%s 不应被淘汰: %v", key, err)
+		}
+	}
+	if cache.Evictions() != 1 {
+		t.Errorf("淘汰计数错误: %d", cache.Evictions())
+	}
+}
+
+// TestMemoryCacheEvictsUntilFits 大条目写入时连续淘汰直到容量足够
+func TestMemoryCacheEvictsUntilFits(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache(t, service.CacheConfig{MaxSize: 20, TTL: time.Minute})
+
+	for i := 0; i < 4; i++ {
+		cache.Set(ctx, fmt.Sprintf("k%d", i), "v", 0)
+	}
+
+	// "big" + "\"0123456789\"" 为 15 字节，需要淘汰三条
+	if err := cache.Set(ctx, "big", "0123456789", 0); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	if cache.Size() > 20 {
+		t.Errorf("缓存大小超出限制: %d", cache.Size())
+	}
+	if cache.Len() != 2 || cache.Evictions() != 3 {
+		t.Errorf("应淘汰三条: len=%d evictions=%d", cache.Len(), cache.Evictions())
+	}
+
+	if err := cache.Set(ctx, "huge", "012345678901234567890", 0); err == nil {
+		t.Error("超过总容量的条目应写入失败")
+	}
+}
+
+// TestMemoryCacheOverwriteSize 覆盖写入不应重复计算大小
+func TestMemoryCacheOverwriteSize(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache(t, service.CacheConfig{})
+
+	for i := 0; i < 100; i++ {
+		cache.Set(ctx, "key", "value", time.Minute)
+	}
+	if cache.Size() != int64(len("key")+len(`"value"`)) {
+		t.Errorf("覆盖写入后大小错误: %d", cache.Size())
+	}
+
+	cache.Set(ctx, "key", "v", time.Minute)
+	if cache.Size() != int64(len("key")+len(`"v"`)) {
+		t.Errorf("覆盖为较小值后大小错误: %d", cache.Size())
+	}
+
+	cache.Delete(ctx, "key")
+	if cache.Size() != 0 || cache.Len() != 0 {
+		t.Errorf("删除后大小错误: size=%d len=%d", cache.Size(), cache.Len())
+	}
+}
+
+// TestMemoryCacheTinyLFU 频繁访问的条目不会被一次性扫描挤出
+func TestMemoryCacheTinyLFU(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestMemoryCache(t, service.CacheConfig{MaxSize: 1000, EvictionPolicy: "tinylfu"})
+
+	var got string
+	hot := make([]string, 20)
+	for i := range hot {
+		hot[i] = fmt.Sprintf("hot-%02d", i)
+		cache.Set(ctx, hot[i], "v", time.Hour)
+	}
+	for round := 0; round < 5; round++ {
+		for _, key := range hot {
+			cache.Get(ctx, key, &got)
+		}
+	}
+
+	// 只访问一次的大量条目
+	for i := 0; i < 500; i++ {
+		cache.Set(ctx, fmt.Sprintf("scan-%03d", i), "v", time.Hour)
+	}
+
+	if cache.Size() > 1000 {
+		t.Errorf("缓存大小超出限制: %d", cache.Size())
+	}
+	for _, key := range hot {
+		if err := cache.Get(ctx, key, &got); err != nil {
+			t.Errorf("热点条目 %s 被淘汰: %v", key, err)
+		}
+	}
+	if cache.Evictions() == 0 {
+		t.Error("扫描条目应被淘汰")
+	}
+}
+
+// BenchmarkMemoryCacheSet 写入满容量缓存的开销，淘汰应为 O(1)
+func BenchmarkMemoryCacheSet(b *testing.B) {
+	ctx := context.Background()
+	cache := service.NewMemoryCache(service.CacheConfig{MaxSize: 1 << 20})
+	defer cache.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(ctx, fmt.Sprintf("key-%d", i), "value", time.Minute)
+	}
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// newRedisLimiters 创建两个共享同一个 Redis 的限流器，模拟两个副本
+func newRedisLimiters(t *testing.T, config service.RateLimitConfig) (*miniredis.Miniredis, *service.RedisRateLimiter, *service.RedisRateLimiter) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	config.Type = "redis"
+	config.RedisURL = "redis://" + mr.Addr()
+
+	a, err := service.NewRedisRateLimiter(config)
+	if err != nil {
+		t.Fatalf("创建限流器失败: %v", err)
+	}
+	b := service.NewRedisRateLimiterWithClient(redis.NewClient(&redis.Options{Addr: mr.Addr()}), config)
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+
+	return mr, a, b
+}
+
+// TestRedisRateLimiterSharedAcrossReplicas 多个副本共享同一个配额
+func TestRedisRateLimiterSharedAcrossReplicas(t *testing.T) {
+	mr, a, b := newRedisLimiters(t, service.RateLimitConfig{DefaultLimit: 4, Window: time.Minute})
+
+	allowed := 0
+	for i := 0; i < 4; i++ {
+		for _, limiter := range []*service.RedisRateLimiter{a, b} {
+			if limiter.Allow("bilibili") {
+				allowed++
+			}
+		}
+	}
+	if allowed != 4 {
+		t.Errorf("两个副本合计应只放行 4 次, got %d", allowed)
+	}
+	if !mr.Exists("glance:ratelimit:bilibili") {
+		t.Errorf("应使用默认前缀保存令牌桶: %v", mr.Keys())
+	}
+
+	// 其他服务不受影响
+	if !b.Allow("weibo") {
+		t.Error("不同服务的配额应相互独立")
+	}
+
+	stats := a.Stats("bilibili")
+	if stats.Remaining != 0 || stats.Capacity != 4 || stats.NextRefill.IsZero() {
+		t.Errorf("状态错误: %+v", stats)
+	}
+
+	a.Reset("bilibili")
+	if !b.Allow("bilibili") {
+		t.Error("Reset 后应恢复配额")
+	}
+}
+
+// TestRedisRateLimiterRefillAndWait 令牌按速率补充，Wait 排队等待并在截止前等不到时立即失败
+func TestRedisRateLimiterRefillAndWait(t *testing.T) {
+	_, a, b := newRedisLimiters(t, service.RateLimitConfig{DefaultLimit: 10, Window: time.Second, Burst: 1})
+
+	if !a.Allow("weibo") || b.Allow("weibo") {
+		t.Fatal("突发容量为 1 时第二次请求应被拒绝")
+	}
+
+	start := time.Now()
+	if err := b.Wait(context.Background(), "weibo"); err != nil {
+		t.Fatalf("Wait 失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("应等待约 100ms, 实际 %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Wait(ctx, "weibo"); !errors.Is(err, service.ErrRateLimitExceeded) {
+		t.Errorf("截止时间内等不到令牌应返回 ErrRateLimitExceeded, got %v", err)
+	}
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if err := a.Wait(expired, "weibo"); !errors.Is(err, service.ErrRateLimitExceeded) {
+		t.Errorf("截止时间已过应立即返回 ErrRateLimitExceeded, got %v", err)
+	}
+
+	if delay := a.Reserve("weibo"); delay < 50*time.Millisecond {
+		t.Errorf("令牌用完后预占应需要等待, got %v", delay)
+	}
+	if stats := b.Stats("weibo"); stats.Reserved != 1 {
+		t.Errorf("另一个副本应看到预占的令牌: %+v", stats)
+	}
+}
+
+// TestRedisRateLimiterFallsBackToLocal Redis 不可用时使用本地令牌桶继续限流
+func TestRedisRateLimiterFallsBackToLocal(t *testing.T) {
+	mr, a, _ := newRedisLimiters(t, service.RateLimitConfig{DefaultLimit: 3, Window: time.Minute})
+	mr.Close()
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if a.Allow("douyu") {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Redis 不可用时应按本地配额放行 3 次, got %d", allowed)
+	}
+	if stats := a.Stats("douyu"); stats.Capacity != 3 || stats.Remaining != 0 {
+		t.Errorf("应返回本地令牌桶的状态: %+v", stats)
+	}
+}
+
+// TestNewRateLimiterSelectsRedis type: redis 时创建分布式限流器
+func TestNewRateLimiterSelectsRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	limiter := service.NewRateLimiter(service.RateLimitConfig{Type: "redis", RedisURL: "redis://" + mr.Addr(), RedisPrefix: "test:"})
+	if _, ok := limiter.(*service.RedisRateLimiter); !ok {
+		t.Fatalf("应创建 RedisRateLimiter, got %T", limiter)
+	}
+	limiter.Allow("zhihu")
+	if !mr.Exists("test:zhihu") {
+		t.Errorf("应使用配置的前缀: %v", mr.Keys())
+	}
+
+	if _, ok := service.NewRateLimiter(service.RateLimitConfig{Type: "redis", RedisURL: "://invalid"}).(*service.TokenBucketLimiter); !ok {
+		t.Error("Redis 地址无效时应使用本地限流")
+	}
+}
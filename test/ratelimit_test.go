@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestTokenBucketRefillsContinuously 令牌按 限额/时间窗口 的速率连续补充，不必等满一分钟
+func TestTokenBucketRefillsContinuously(t *testing.T) {
+	limiter := service.NewRateLimiter(service.RateLimitConfig{
+		DefaultLimit: 10,
+		Window:       time.Second,
+		Burst:        1,
+	})
+
+	if !limiter.Allow("bilibili") {
+		t.Fatal("初始应有令牌")
+	}
+	if limiter.Allow("bilibili") {
+		t.Fatal("突发容量为 1 时不应连续放行")
+	}
+
+	// 每秒 10 个令牌，约 100ms 补充一个
+	time.Sleep(150 * time.Millisecond)
+	if !limiter.Allow("bilibili") {
+		t.Error("150ms 后应补充一个令牌")
+	}
+	if limiter.Allow("bilibili") {
+		t.Error("不应一次补满")
+	}
+}
+
+// TestTokenBucketBurst 突发容量与速率分别配置
+func TestTokenBucketBurst(t *testing.T) {
+	limiter := service.NewRateLimiter(service.RateLimitConfig{
+		DefaultLimit: 60,
+		Bursts:       map[string]int{"weibo": 5},
+	})
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if limiter.Allow("weibo") {
+			allowed++
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("突发容量为 5 时应放行 5 次, got %d", allowed)
+	}
+
+	// 未单独配置突发容量的服务使用限额作为容量
+	allowed = 0
+	for i := 0; i < 100; i++ {
+		if limiter.Allow("zhihu") {
+			allowed++
+		}
+	}
+	if allowed != 60 {
+		t.Errorf("默认突发容量应等于限额, got %d", allowed)
+	}
+}
+
+// TestMergeRateLimits api-sources 中的 rate-limit 参与限流，rate-limit.services 优先
+func TestMergeRateLimits(t *testing.T) {
+	merged := service.MergeRateLimits(
+		service.RateLimitConfig{Services: map[string]int{"zhihu": 30}},
+		map[string]service.APISourceConfig{
+			"bilibili": {RateLimit: 100},
+			"zhihu":    {RateLimit: 50},
+			"gitee":    {},
+		},
+	)
+
+	cases := map[string]int{"bilibili": 100, "zhihu": 30, "gitee": 60}
+	for source, want := range cases {
+		if limit, window := merged.Limit(source); limit != want || window != time.Minute {
+			t.Errorf("%s: got %d/%v, want %d/1m", source, limit, window, want)
+		}
+	}
+}
+
+// TestAPISourceRateLimitFeedsLimiter 服务管理器按 api-sources 中的 rate-limit 限流
+func TestAPISourceRateLimitFeedsLimiter(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{})
+
+	sm := service.NewServiceManager(&service.Config{
+		APISources: map[string]service.APISourceConfig{
+			"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, RateLimit: 2},
+		},
+	})
+
+	var failed int
+	for i := 0; i < 3; i++ {
+		if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "POST", Path: "/x"}); err != nil {
+			failed++
+		}
+	}
+	if failed != 1 || upstream.Hits() != 2 {
+		t.Errorf("每分钟 2 次的配额下应放行 2 次, 失败 %d 次, 回源 %d 次", failed, upstream.Hits())
+	}
+}
+
+// TestRateLimiterReserveAndStats 预占令牌返回需要等待的时长，Stats 报告剩余和预占的令牌
+func TestRateLimiterReserveAndStats(t *testing.T) {
+	limiter := service.NewRateLimiter(service.RateLimitConfig{DefaultLimit: 10, Window: time.Second, Burst: 2})
+
+	if delay := limiter.Reserve("bilibili"); delay != 0 {
+		t.Errorf("有令牌时不需要等待, got %v", delay)
+	}
+	if stats := limiter.Stats("bilibili"); stats.Remaining != 1 || stats.Capacity != 2 || stats.NextRefill.IsZero() {
+		t.Errorf("状态错误: %+v", stats)
+	}
+
+	limiter.Reserve("bilibili")
+	delay := limiter.Reserve("bilibili")
+	if delay < 80*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("令牌用完后应等待约 100ms, got %v", delay)
+	}
+	if delay := limiter.Reserve("bilibili"); delay < 180*time.Millisecond || delay > 200*time.Millisecond {
+		t.Errorf("第二个预占应等待约 200ms, got %v", delay)
+	}
+
+	stats := limiter.Stats("bilibili")
+	if stats.Remaining != 0 || stats.Reserved != 2 {
+		t.Errorf("应有 2 个预占令牌: %+v", stats)
+	}
+	if until := time.Until(stats.NextRefill); until <= 0 || until > 100*time.Millisecond {
+		t.Errorf("下一个令牌应在 100ms 内补充, got %v", until)
+	}
+
+	if stats := limiter.Stats("zhihu"); stats.Remaining != 2 || !stats.NextRefill.IsZero() {
+		t.Errorf("未使用的服务令牌应为满: %+v", stats)
+	}
+}
+
+// TestRateLimiterWait Wait 阻塞到有令牌为止，截止时间内等不到时立即返回错误并且不占用令牌
+func TestRateLimiterWait(t *testing.T) {
+	limiter := service.NewRateLimiter(service.RateLimitConfig{DefaultLimit: 10, Window: time.Second, Burst: 1})
+	limiter.Allow("weibo")
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), "weibo"); err != nil {
+		t.Fatalf("Wait 失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("应等待约 100ms, 实际 %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := limiter.Wait(ctx, "weibo"); !errors.Is(err, service.ErrRateLimitExceeded) {
+		t.Errorf("截止时间内等不到令牌应返回 ErrRateLimitExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("应立即返回, 实际 %v", elapsed)
+	}
+	if stats := limiter.Stats("weibo"); stats.Reserved != 0 {
+		t.Errorf("失败的 Wait 不应预占令牌: %+v", stats)
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if err := limiter.Wait(expired, "weibo"); !errors.Is(err, service.ErrRateLimitExceeded) {
+		t.Errorf("截止时间已过应立即返回 ErrRateLimitExceeded, got %v", err)
+	}
+	if stats := limiter.Stats("weibo"); stats.Reserved != 0 {
+		t.Errorf("截止时间已过的 Wait 不应预占令牌: %+v", stats)
+	}
+
+	// 等待期间取消时归还令牌
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if err := limiter.Wait(ctx, "weibo"); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后应返回 context.Canceled, got %v", err)
+	}
+	if stats := limiter.Stats("weibo"); stats.Reserved != 0 {
+		t.Errorf("取消的 Wait 应归还令牌: %+v", stats)
+	}
+}
+
+// TestServiceManagerRateLimitWait 页面请求默认立即失败，配置 max-wait 或后台请求时排队等待
+func TestServiceManagerRateLimitWait(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{})
+
+	newManager := func(maxWait time.Duration) *service.ServiceManager {
+		return service.NewServiceManager(&service.Config{
+			APISources: map[string]service.APISourceConfig{
+				"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+			},
+			RateLimit: service.RateLimitConfig{DefaultLimit: 10, Window: time.Second, Burst: 1, MaxWait: maxWait},
+		})
+	}
+	request := func(ctx context.Context, sm *service.ServiceManager) error {
+		_, err := sm.RequestWithFallback(ctx, "douyu", &service.APIRequest{Method: "POST", Path: "/x"})
+		return err
+	}
+
+	sm := newManager(0)
+	request(context.Background(), sm)
+	if err := request(context.Background(), sm); !errors.Is(err, service.ErrRateLimitExceeded) {
+		t.Errorf("未配置 max-wait 时应立即失败, got %v", err)
+	}
+	if err := request(service.WithRateLimitWait(context.Background()), sm); err != nil {
+		t.Errorf("后台请求应排队等待: %v", err)
+	}
+
+	sm = newManager(500 * time.Millisecond)
+	request(context.Background(), sm)
+	if err := request(context.Background(), sm); err != nil {
+		t.Errorf("max-wait 内可取得令牌时应等待: %v", err)
+	}
+}
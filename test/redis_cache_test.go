@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+func newTestRedisCache(t *testing.T, prefix string) (*service.RedisCache, *miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	cache := service.NewRedisCacheWithClient(client, service.CacheConfig{
+		TTL:         time.Minute,
+		RedisPrefix: prefix,
+	})
+
+	return cache, server, client
+}
+
+// TestRedisCacheRoundTrip Redis缓存读写测试
+func TestRedisCacheRoundTrip(t *testing.T) {
+	cache, server, _ := newTestRedisCache(t, "glance-test:")
+	ctx := context.Background()
+
+	want := map[string]interface{}{"title": "知乎热榜", "limit": float64(15)}
+	if err := cache.Set(ctx, "zhihu-trending", want, 0); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := cache.Get(ctx, "zhihu-trending", &got); err != nil {
+		t.Fatalf("读取缓存失败: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("缓存内容不一致: got %v, want %v", got, want)
+	}
+
+	if keys := server.Keys(); !reflect.DeepEqual(keys, []string{"glance-test:zhihu-trending"}) {
+		t.Errorf("键前缀错误: %v", keys)
+	}
+
+	if err := cache.Delete(ctx, "zhihu-trending"); err != nil {
+		t.Fatalf("删除缓存失败: %v", err)
+	}
+	if err := cache.Get(ctx, "zhihu-trending", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("删除后应返回 ErrCacheMiss, got %v", err)
+	}
+}
+
+// TestRedisCacheTTL Redis缓存过期测试
+func TestRedisCacheTTL(t *testing.T) {
+	cache, server, _ := newTestRedisCache(t, "")
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "short", "value", 50*time.Millisecond); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	server.FastForward(100 * time.Millisecond)
+
+	var got string
+	if err := cache.Get(ctx, "short", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("过期后应返回 ErrCacheMiss, got %v (%q)", err, got)
+	}
+}
+
+// TestRedisCacheClearScopedToPrefix Clear 只删除本前缀下的键
+func TestRedisCacheClearScopedToPrefix(t *testing.T) {
+	cache, server, client := newTestRedisCache(t, "glance[1]:")
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(ctx, key, key, 0); err != nil {
+			t.Fatalf("写入缓存失败: %v", err)
+		}
+	}
+	// 同一Redis中其他应用的数据
+	if err := client.Set(ctx, "other:key", "keep", 0).Err(); err != nil {
+		t.Fatalf("写入其他数据失败: %v", err)
+	}
+
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("清空缓存失败: %v", err)
+	}
+
+	if keys := server.Keys(); !reflect.DeepEqual(keys, []string{"other:key"}) {
+		t.Errorf("Clear 影响了前缀外的键或未清理干净: %v", keys)
+	}
+}
+
+// TestRedisCacheHealth Redis连接健康检查
+func TestRedisCacheHealth(t *testing.T) {
+	cache, server, _ := newTestRedisCache(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if !cache.IsHealthy(ctx) {
+		t.Fatal("Redis可用时应报告健康")
+	}
+
+	server.Close()
+
+	if cache.IsHealthy(ctx) {
+		t.Error("Redis不可用时应报告异常")
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestRequestWithFallbackSingleFetch 成功的请求只回源一次，并返回工作池中拿到的响应
+func TestRequestWithFallbackSingleFetch(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Body: `{"code":0}`})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{})
+
+	resp, err := sm.RequestWithFallback(context.Background(), "bilibili", &service.APIRequest{Method: "GET", Path: "/x"})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if string(resp.Body) != `{"code":0}` {
+		t.Errorf("响应体错误: %s", resp.Body)
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("应只回源 1 次, got %d", got)
+	}
+}
+
+// TestRequestWithFallbackStatusError 4xx/5xx 响应返回 *service.APIError
+func TestRequestWithFallbackStatusError(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Status: http.StatusServiceUnavailable, Body: "busy"})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{})
+
+	_, err := sm.RequestWithFallback(context.Background(), "bilibili", &service.APIRequest{Method: "GET", Path: "/x"})
+
+	var apiErr *service.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("应返回 APIError, got %v", err)
+	}
+	if apiErr.Service != "bilibili" || apiErr.StatusCode != http.StatusServiceUnavailable || string(apiErr.Body) != "busy" {
+		t.Errorf("APIError 内容错误: %+v", apiErr)
+	}
+}
+
+// TestRequestWithFallbackUsesLimitedFallback 主服务失败时使用备用服务，备用服务同样受限流约束
+func TestRequestWithFallbackUsesLimitedFallback(t *testing.T) {
+	primary := newUpstream(t, upstreamConfig{Status: http.StatusInternalServerError})
+	fallback := newUpstream(t, upstreamConfig{Body: `{"ok":true}`})
+
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"bilibili": {BaseURL: primary.URL, Timeout: 5 * time.Second, Fallbacks: []string{"weibo"}},
+		"weibo":    {BaseURL: fallback.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{Services: map[string]int{"weibo": 1}})
+
+	req := &service.APIRequest{Method: "POST", Path: "/x"}
+	resp, err := sm.RequestWithFallback(context.Background(), "bilibili", req)
+	if err != nil {
+		t.Fatalf("备用服务请求失败: %v", err)
+	}
+	if string(resp.Body) != `{"ok":true}` {
+		t.Errorf("应返回备用服务的响应: %s", resp.Body)
+	}
+
+	// 备用服务的限额已用完，再次请求不应回源
+	_, err = sm.RequestWithFallback(context.Background(), "bilibili", req)
+	if err == nil {
+		t.Fatal("备用服务超出限流时应返回错误")
+	}
+	var apiErr *service.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("最终错误应包含主服务的 APIError: %v", err)
+	}
+	if got := primary.Hits(); got != 2 {
+		t.Errorf("主服务应请求 2 次, got %d", got)
+	}
+	if got := fallback.Hits(); got != 1 {
+		t.Errorf("备用服务应只请求 1 次, got %d", got)
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestRetryTransientStatus 可重试的状态码按退避策略重试，并记录重试次数
+func TestRetryTransientStatus(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 2, Failure: upstreamResponse{Status: http.StatusBadGateway}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}},
+	}, service.RateLimitConfig{})
+
+	resp, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "GET", Path: "/x"})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("重试后应成功: %v", err)
+	}
+	if got := upstream.Hits(); got != 3 {
+		t.Errorf("应请求 3 次, got %d", got)
+	}
+	if got := sm.GetMetrics().APIRetries["douyu"]; got != 2 {
+		t.Errorf("应记录 2 次重试, got %d", got)
+	}
+	if got := sm.GetMetrics().APIRequests["douyu"]; got != 1 {
+		t.Errorf("重试不应重复计入请求数, got %d", got)
+	}
+}
+
+// TestRetryGivesUpAfterMaxAttempts 超过最大尝试次数后返回最后一次的错误
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 10, Failure: upstreamResponse{Status: http.StatusServiceUnavailable}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond}},
+	}, service.RateLimitConfig{})
+
+	if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "GET", Path: "/x"}); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if got := upstream.Hits(); got != 2 {
+		t.Errorf("应请求 2 次, got %d", got)
+	}
+}
+
+// TestRetrySkipsNonRetryable 不可重试的状态码和非幂等请求不重试
+func TestRetrySkipsNonRetryable(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusNotFound}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}},
+	}, service.RateLimitConfig{})
+
+	if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "GET", Path: "/x"}); err == nil {
+		t.Error("404 应返回错误")
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("404 不应重试, got %d", got)
+	}
+
+	upstream = newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusBadGateway}})
+	sm = newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond}},
+	}, service.RateLimitConfig{})
+
+	if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "POST", Path: "/x"}); err == nil {
+		t.Error("POST 请求不应重试")
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("POST 请求不应重试, got %d", got)
+	}
+}
+
+// TestRetryHonorsRetryAfter 上游返回 Retry-After 时至少等待该时长
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"1"}}}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}},
+	}, service.RateLimitConfig{})
+
+	start := time.Now()
+	if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "GET", Path: "/x"}); err != nil {
+		t.Fatalf("重试后应成功: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("应等待 Retry-After 指定的时长, 实际 %v", elapsed)
+	}
+}
+
+// TestRetryGivesUpOnLongRetryAfter Retry-After 超过 max-delay 时不再重试，直接返回错误
+func TestRetryGivesUpOnLongRetryAfter(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"60"}}}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}},
+	}, service.RateLimitConfig{})
+
+	start := time.Now()
+	if _, err := sm.RequestWithFallback(context.Background(), "douyu", &service.APIRequest{Method: "GET", Path: "/x"}); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("不应等待 Retry-After, 实际 %v", elapsed)
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("不应重试, got %d", got)
+	}
+}
+
+// TestRetryStopsWhenContextDone 等待重试期间上下文结束时立即返回
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 10, Failure: upstreamResponse{Status: http.StatusServiceUnavailable}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}},
+	}, service.RateLimitConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := sm.RequestWithFallback(ctx, "douyu", &service.APIRequest{Method: "GET", Path: "/x"}); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("上下文结束后应停止等待, 实际 %v", elapsed)
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("应只请求 1 次, got %d", got)
+	}
+}
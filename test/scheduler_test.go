@@ -0,0 +1,208 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/server"
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// counterWidget 记录数据加载次数的测试组件，缓存时长很短以便观察后台刷新；
+// requests 大于 0 时每次加载经服务管理器向 douyu 源发出 requests 个请求
+type counterWidget struct {
+	widget.BaseWidget `yaml:",inline"`
+	Name              string `yaml:"name"`
+	Requests          int    `yaml:"requests"`
+}
+
+var counterLoads = struct {
+	counts map[string]int
+	mu     sync.Mutex
+}{counts: make(map[string]int)}
+
+func init() {
+	widget.RegisterWidget("test-counter", func() widget.Widget {
+		return &counterWidget{BaseWidget: widget.BaseWidget{Type: "test-counter"}}
+	})
+}
+
+func (w *counterWidget) GetData(ctx context.Context, config widget.Config) (interface{}, error) {
+	for i := 0; i < w.Requests; i++ {
+		sm := ctx.Value("serviceManager").(*service.ServiceManager)
+		req := &service.APIRequest{Method: "GET", Path: fmt.Sprintf("/%s/%d", w.Name, i)}
+		if _, err := sm.RequestWithFallback(ctx, "douyu", req); err != nil {
+			return nil, err
+		}
+	}
+
+	counterLoads.mu.Lock()
+	defer counterLoads.mu.Unlock()
+
+	counterLoads.counts[w.Name]++
+	return map[string]interface{}{"title": w.Name}, nil
+}
+
+func (w *counterWidget) GetCacheKey(config widget.Config) string {
+	return widget.CacheKey(w, config)
+}
+
+func (w *counterWidget) GetCacheDuration() time.Duration {
+	return 200 * time.Millisecond
+}
+
+func counterLoadCount(name string) int {
+	counterLoads.mu.Lock()
+	defer counterLoads.mu.Unlock()
+	return counterLoads.counts[name]
+}
+
+// startTestServer 在随机端口上运行完整服务（含后台刷新），返回服务地址
+func startTestServer(t *testing.T, yaml string) string {
+	t.Helper()
+
+	counterLoads.mu.Lock()
+	counterLoads.counts = make(map[string]int)
+	counterLoads.mu.Unlock()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取端口失败: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	path := filepath.Join(t.TempDir(), "glance.yml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg.Server.Host, cfg.Server.Port = "127.0.0.1", port
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务失败: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	addr := fmt.Sprintf("http://127.0.0.1:%d", port)
+	waitFor(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, "服务未启动")
+
+	return addr
+}
+
+// TestSchedulerPrewarmsAndPausesIdlePages 启动后预热并在过期前刷新，页面闲置后暂停，再次访问后恢复
+func TestSchedulerPrewarmsAndPausesIdlePages(t *testing.T) {
+	addr := startTestServer(t, `
+server:
+  rate-limit:
+    default-limit: 6000
+  refresh:
+    enabled: true
+    idle-timeout: 700ms
+pages:
+  - name: 预热
+    slug: warm
+    columns:
+      - size: full
+        widgets:
+          - type: test-counter
+            name: warm
+`)
+
+	waitFor(t, func() bool { return counterLoadCount("warm") >= 1 }, "未在访问前预热")
+	waitFor(t, func() bool { return counterLoadCount("warm") >= 3 }, "未在缓存过期前刷新")
+
+	// 超过闲置时长后不再刷新
+	time.Sleep(700 * time.Millisecond)
+	paused := counterLoadCount("warm")
+	time.Sleep(500 * time.Millisecond)
+	if got := counterLoadCount("warm"); got > paused+1 {
+		t.Errorf("闲置页面不应继续刷新: %d -> %d", paused, got)
+	}
+
+	if status, _ := getPage(t, addr+"/warm"); status != 200 {
+		t.Fatalf("页面访问失败: %d", status)
+	}
+	resumed := counterLoadCount("warm")
+	waitFor(t, func() bool { return counterLoadCount("warm") >= resumed+2 }, "再次访问后未恢复刷新")
+}
+
+// TestSchedulerRespectsRateLimit 后台刷新发出的上游请求不超过该源的限流配额
+func TestSchedulerRespectsRateLimit(t *testing.T) {
+	var hits int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+
+	startTestServer(t, fmt.Sprintf(`
+server:
+  api-sources:
+    douyu:
+      base-url: %s
+  rate-limit:
+    window: 1s
+    services:
+      douyu: 4
+  refresh:
+    enabled: true
+pages:
+  - name: 限流
+    columns:
+      - size: full
+        widgets:
+          - type: test-counter
+            name: limited-a
+            requests: 2
+          - type: test-counter
+            name: limited-b
+            requests: 2
+          - type: test-counter
+            name: limited-c
+            requests: 2
+`, upstream.URL))
+
+	start := time.Now()
+	waitFor(t, func() bool {
+		return counterLoadCount("limited-a") >= 1 && counterLoadCount("limited-b") >= 1 && counterLoadCount("limited-c") >= 1
+	}, "未预热全部组件")
+
+	// 组件每 200ms 过期一次，不限流时每秒约 30 个请求；配额为突发 4 个加每秒 4 个
+	time.Sleep(time.Second)
+	allowed := 4 + 4*time.Since(start).Seconds()
+	if got := atomic.LoadInt32(&hits); float64(got) > allowed+1 {
+		t.Errorf("%v 内最多应请求 %.0f 次, got %d", time.Since(start), allowed, got)
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/linzi007/glance-china/internal/config"
+	"github.com/linzi007/glance-china/internal/server"
+)
+
+const testServerConfig = `
+server:
+  port: 8080
+pages:
+  - name: 首页
+    columns:
+      - size: full
+        widgets:
+          - type: clock
+  - name: 技术
+    slug: tech
+    columns:
+      - size: small
+        widgets:
+          - type: clock
+            title: 时钟
+      - size: full
+`
+
+func newTestServer(t *testing.T, yaml string) *httptest.Server {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "glance.yml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if err := config.ValidateConfig(cfg); err != nil {
+		t.Fatalf("配置校验失败: %v", err)
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务失败: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func getPage(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("读取响应失败: %v", err)
+	}
+
+	return resp.StatusCode, string(body)
+}
+
+// TestServerRendersPageBySlug /<slug> 渲染对应页面的列和组件
+func TestServerRendersPageBySlug(t *testing.T) {
+	ts := newTestServer(t, testServerConfig)
+
+	status, body := getPage(t, ts.URL+"/tech")
+	if status != http.StatusOK {
+		t.Fatalf("状态码错误: %d", status)
+	}
+	for _, want := range []string{"<title>技术", "column-small", "column-full", "widget-clock", `href="/tech"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("页面缺少 %q", want)
+		}
+	}
+	// 未注册的组件类型渲染为错误提示，不影响页面其他部分
+	if !strings.Contains(body, "unsupported widget type: clock") {
+		t.Error("未注册组件应显示错误提示")
+	}
+}
+
+// TestServerIndexAndNotFound 首页渲染第一个页面，未知路径返回 404
+func TestServerIndexAndNotFound(t *testing.T) {
+	ts := newTestServer(t, testServerConfig)
+
+	status, body := getPage(t, ts.URL+"/")
+	if status != http.StatusOK || !strings.Contains(body, "<title>首页") {
+		t.Errorf("首页应渲染第一个页面: %d", status)
+	}
+
+	if status, _ := getPage(t, ts.URL+"/missing"); status != http.StatusNotFound {
+		t.Errorf("未知页面应返回 404, got %d", status)
+	}
+
+	if status, body := getPage(t, ts.URL+"/health"); status != http.StatusOK || !strings.Contains(body, `"status":"ok"`) {
+		t.Errorf("健康检查错误: %d %s", status, body)
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestGiteeClientPaginatesWithLinkHeader 按 Link 响应头翻页，并使用 api-sources 中的 token
+func TestGiteeClientPaginatesWithLinkHeader(t *testing.T) {
+	var tokens []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/repos/owner/repo/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Has("access_token") {
+			t.Errorf("token 不应出现在 URL 中: %s", r.URL)
+		}
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		if state := r.URL.Query().Get("state"); state != "open" {
+			t.Errorf("默认应只查询打开的 Issue, got %q", state)
+		}
+
+		page := r.URL.Query().Get("page")
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v5/repos/owner/repo/issues?page=2&per_page=2>; rel="next", <http://%s/api/v5/repos/owner/repo/issues?page=3&per_page=2>; rel="last"`, r.Host, r.Host))
+		}
+		if page == "2" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v5/repos/owner/repo/issues?page=3&per_page=2>; rel="next"`, r.Host))
+		}
+		fmt.Fprintf(w, `[{"number":"I%s1","title":"issue"},{"number":"I%s2","title":"issue"}]`, page, page)
+	}))
+	defer upstream.Close()
+
+	client := service.NewGiteeClient(service.APISourceConfig{
+		BaseURL: upstream.URL + "/api/v5",
+		Token:   "config-token",
+		Timeout: 5 * time.Second,
+	})
+
+	issues, err := client.ListIssues(context.Background(), "owner/repo", service.GiteeListOptions{PerPage: 2, MaxPages: 2})
+	if err != nil {
+		t.Fatalf("获取 Issue 失败: %v", err)
+	}
+	if len(issues) != 4 || issues[2].Number != "I21" {
+		t.Errorf("应读取前两页: %+v", issues)
+	}
+	if len(tokens) != 2 || tokens[0] != "token config-token" || tokens[1] != "token config-token" {
+		t.Errorf("每页都应携带配置的 token: %v", tokens)
+	}
+
+	// 组件 token 覆盖配置的 token
+	tokens = nil
+	if _, err := client.WithToken("widget-token").ListIssues(context.Background(), "owner/repo", service.GiteeListOptions{}); err != nil {
+		t.Fatalf("获取 Issue 失败: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0] != "token widget-token" {
+		t.Errorf("应使用组件 token: %v", tokens)
+	}
+}
+
+// TestGiteeClientErrorStatus 上游返回错误状态码时返回错误
+func TestGiteeClientErrorStatus(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	defer upstream.Close()
+
+	client := service.NewGiteeClient(service.APISourceConfig{BaseURL: upstream.URL, Timeout: 5 * time.Second})
+	if _, err := client.GetRepository(context.Background(), "owner/missing"); err == nil {
+		t.Error("404 应返回错误")
+	}
+}
+
+// TestZhihuClientQuestionAndColumn 获取问题详情和专栏文章
+func TestZhihuClientQuestionAndColumn(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/questions/42":
+			w.Write([]byte(`{"id":42,"title":"问题","answer_count":3}`))
+		case "/api/v4/columns/c_1/items":
+			if r.URL.Query().Get("offset") != "10" {
+				t.Errorf("翻页参数错误: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"data":[{"id":1,"title":"文章","voteup_count":8,"author":{"name":"作者"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	client := service.NewZhihuClient(service.APISourceConfig{BaseURL: upstream.URL + "/api", Timeout: 5 * time.Second})
+
+	question, err := client.GetQuestion(context.Background(), "42")
+	if err != nil || question.Title != "问题" || question.AnswerCount != 3 {
+		t.Errorf("问题详情错误: %v %+v", err, question)
+	}
+
+	articles, err := client.GetColumnArticles(context.Background(), "c_1", 10, 10)
+	if err != nil || len(articles) != 1 || articles[0].Author.Name != "作者" {
+		t.Errorf("专栏文章错误: %v %+v", err, articles)
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// countingLoader 记录调用次数的 LoadFunc
+type countingLoader struct {
+	calls int32
+	value string
+	err   error
+	delay time.Duration
+}
+
+func (l *countingLoader) load(ctx context.Context) (interface{}, error) {
+	atomic.AddInt32(&l.calls, 1)
+	time.Sleep(l.delay)
+	return l.value, l.err
+}
+
+func (l *countingLoader) count() int32 {
+	return atomic.LoadInt32(&l.calls)
+}
+
+// waitFor 等待条件成立，超时则测试失败
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestStaleCacheServesStaleWhileRefreshing 过期后立即返回旧数据，并只触发一次后台刷新
+func TestStaleCacheServesStaleWhileRefreshing(t *testing.T) {
+	ctx := context.Background()
+	memory := newTestMemoryCache(t, service.CacheConfig{})
+	cache := service.NewStaleCache(memory, time.Minute)
+
+	loader := &countingLoader{value: "v1"}
+	var got string
+	if _, err := cache.Fetch(ctx, "key", 50*time.Millisecond, &got, loader.load); err != nil || got != "v1" {
+		t.Fatalf("首次读取失败: %v (%q)", err, got)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+
+	// 上游变慢：过期数据应立即返回
+	loader.value, loader.delay = "v2", 100*time.Millisecond
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var got string
+			start := time.Now()
+			status, err := cache.Fetch(ctx, "key", 50*time.Millisecond, &got, loader.load)
+			if err != nil || got != "v1" || !status.Stale {
+				t.Errorf("应返回过期数据: %v (%q, %+v)", err, got, status)
+			}
+			if status.StaleSince.IsZero() || status.StaleSince.After(time.Now()) {
+				t.Errorf("过期时间错误: %v", status.StaleSince)
+			}
+			if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+				t.Errorf("返回过期数据不应等待上游: %v", elapsed)
+			}
+		}()
+	}
+	wg.Wait()
+
+	waitFor(t, func() bool {
+		var got string
+		status, _ := cache.Fetch(ctx, "key", time.Minute, &got, loader.load)
+		return !status.Stale && got == "v2"
+	}, "后台刷新未完成")
+
+	if loader.count() != 2 {
+		t.Errorf("后台刷新应只执行一次, 共调用 %d 次", loader.count())
+	}
+}
+
+// TestStaleCacheRefreshFailureKeepsStale 刷新失败时继续返回旧数据
+func TestStaleCacheRefreshFailureKeepsStale(t *testing.T) {
+	ctx := context.Background()
+	cache := service.NewStaleCache(newTestMemoryCache(t, service.CacheConfig{}), time.Minute)
+
+	loader := &countingLoader{value: "v1"}
+	var got string
+	cache.Fetch(ctx, "key", 20*time.Millisecond, &got, loader.load)
+
+	time.Sleep(40 * time.Millisecond)
+	loader.err = errors.New("rate limited")
+
+	for i := 0; i < 2; i++ {
+		status, err := cache.Fetch(ctx, "key", 20*time.Millisecond, &got, loader.load)
+		if err != nil || got != "v1" || !status.Stale {
+			t.Errorf("刷新失败时应返回过期数据: %v (%q, %+v)", err, got, status)
+		}
+		waitFor(t, func() bool { return loader.count() == int32(i+2) }, "未触发后台刷新")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStaleCacheGraceExpired 超过宽限期后同步获取新数据
+func TestStaleCacheGraceExpired(t *testing.T) {
+	ctx := context.Background()
+	cache := service.NewStaleCache(newTestMemoryCache(t, service.CacheConfig{}), 30*time.Millisecond)
+
+	loader := &countingLoader{value: "v1"}
+	var got string
+	cache.Fetch(ctx, "key", 20*time.Millisecond, &got, loader.load)
+
+	time.Sleep(80 * time.Millisecond)
+	loader.value = "v2"
+
+	status, err := cache.Fetch(ctx, "key", 20*time.Millisecond, &got, loader.load)
+	if err != nil || got != "v2" || status.Stale {
+		t.Errorf("超过宽限期应返回新数据: %v (%q, %+v)", err, got, status)
+	}
+}
+
+// TestStaleCacheLoadError 无缓存数据时返回上游错误
+func TestStaleCacheLoadError(t *testing.T) {
+	cache := service.NewStaleCache(newTestMemoryCache(t, service.CacheConfig{}), time.Minute)
+
+	upstream := errors.New("upstream unavailable")
+	loader := &countingLoader{err: upstream}
+
+	var got string
+	if _, err := cache.Fetch(context.Background(), "key", time.Minute, &got, loader.load); !errors.Is(err, upstream) {
+		t.Errorf("应返回上游错误, got %v", err)
+	}
+}
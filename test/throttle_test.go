@@ -0,0 +1,207 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// TestBilibiliRiskControlBacksOff Bilibili 的 code -412 风控响应触发退避：配额减半，退避期内不再请求上游
+func TestBilibiliRiskControlBacksOff(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Body: `{"code":-412,"message":"请求被拦截"}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"bilibili": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{DefaultLimit: 100, Adaptive: service.AdaptiveConfig{Backoff: time.Minute}})
+
+	err := getX(sm, "bilibili")
+	var throttleErr *service.ThrottleError
+	if !errors.As(err, &throttleErr) || !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Fatalf("风控响应应返回 ThrottleError, got %v", err)
+	}
+	if time.Until(throttleErr.Until) < 50*time.Second {
+		t.Errorf("应退避约 1 分钟, until %v", throttleErr.Until)
+	}
+
+	if err := getX(sm, "bilibili"); !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Errorf("退避期内应直接返回限流错误, got %v", err)
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("退避期内不应请求上游, got %d", got)
+	}
+
+	if got := sm.GetRateLimiter().Stats("bilibili").Capacity; got != 50 {
+		t.Errorf("配额应减半, capacity %d", got)
+	}
+	metrics := sm.GetMetrics()
+	if metrics.APIThrottles["bilibili"] != 1 || metrics.RateFactors["bilibili"] != 0.5 || metrics.ThrottledUntil["bilibili"].IsZero() {
+		t.Errorf("监控应记录限流状态: %v %v %v", metrics.APIThrottles, metrics.RateFactors, metrics.ThrottledUntil)
+	}
+	if status := sm.ThrottleStates()["bilibili"]; !status.Throttled || status.Hits != 1 {
+		t.Errorf("限流状态错误: %+v", status)
+	}
+	if got := sm.CircuitBreakers()["bilibili"].Failures; got != 0 {
+		t.Errorf("限流信号不应计入熔断失败, got %d", got)
+	}
+}
+
+// TestThrottleNotRetried 限流响应不按重试策略重试，直接进入退避
+func TestThrottleNotRetried(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusTooManyRequests, Body: `{}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {
+			BaseURL: upstream.URL,
+			Timeout: 5 * time.Second,
+			Retry:   service.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, RetryableStatus: []int{http.StatusTooManyRequests}},
+		},
+	}, service.RateLimitConfig{DefaultLimit: 100, Adaptive: service.AdaptiveConfig{Backoff: time.Minute}})
+
+	if err := getX(sm, "douyu"); !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Fatalf("应返回限流错误, got %v", err)
+	}
+	if got := upstream.Hits(); got != 1 {
+		t.Errorf("限流响应不应重试, got %d", got)
+	}
+}
+
+// TestRetryConsumesRateLimit 每次重试都占用限流配额，配额用完时停止重试
+func TestRetryConsumesRateLimit(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 10, Failure: upstreamResponse{Status: http.StatusServiceUnavailable, Body: `{}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second, Retry: service.RetryConfig{MaxAttempts: 5, BaseDelay: time.Millisecond}},
+	}, service.RateLimitConfig{DefaultLimit: 2, Window: time.Hour})
+
+	if err := getX(sm, "douyu"); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if got := upstream.Hits(); got != 2 {
+		t.Errorf("配额为 2 时应只请求 2 次, got %d", got)
+	}
+	if got := sm.GetRateLimiter().Stats("douyu").Remaining; got != 0 {
+		t.Errorf("重试应消耗令牌, remaining %d", got)
+	}
+}
+
+// TestThrottleSignalsPerClient 各客户端识别各自的限流信号：微博 418 是限流，其他源的 418 仍是普通错误
+func TestThrottleSignalsPerClient(t *testing.T) {
+	weibo := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusTeapot, Body: `{}`}})
+	zhihu := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusTeapot, Body: `{}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"weibo": {BaseURL: weibo.URL, Timeout: 5 * time.Second},
+		"zhihu": {BaseURL: zhihu.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{DefaultLimit: 100})
+
+	if err := getX(sm, "weibo"); !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Errorf("微博 418 应视为限流, got %v", err)
+	}
+
+	err := getX(sm, "zhihu")
+	var apiErr *service.APIError
+	if !errors.As(err, &apiErr) || errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Errorf("知乎 418 应返回 APIError, got %v", err)
+	}
+}
+
+// TestThrottleRecoversGradually 退避结束后恢复请求，配额按恢复间隔逐步加倍直到恢复配置值
+func TestThrottleRecoversGradually(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 2, Failure: upstreamResponse{Status: http.StatusTooManyRequests, Body: `{}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{DefaultLimit: 100, Adaptive: service.AdaptiveConfig{Backoff: 50 * time.Millisecond, RecoveryInterval: 100 * time.Millisecond}})
+
+	getX(sm, "douyu")
+	time.Sleep(60 * time.Millisecond)
+
+	// 退避结束后紧接着再次触发，退避时长加倍，配额降为 1/4
+	if err := getX(sm, "douyu"); !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Fatalf("应再次触发限流, got %v", err)
+	}
+	if err := getX(sm, "douyu"); !errors.Is(err, service.ErrUpstreamThrottled) {
+		t.Fatalf("退避期内应返回限流错误, got %v", err)
+	}
+	if got := sm.ThrottleStates()["douyu"].Factor; got != 0.25 {
+		t.Fatalf("配额应降为 1/4, got %v", got)
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	if err := getX(sm, "douyu"); err != nil {
+		t.Fatalf("退避结束后应恢复请求: %v", err)
+	}
+	if got := sm.ThrottleStates()["douyu"].Factor; got != 0.25 {
+		t.Errorf("退避刚结束时应保持缩小的配额, got %v", got)
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	getX(sm, "douyu")
+	if got := sm.ThrottleStates()["douyu"].Factor; got != 0.5 {
+		t.Errorf("一个恢复间隔后配额应加倍, got %v", got)
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	getX(sm, "douyu")
+	if status := sm.ThrottleStates()["douyu"]; status.Factor != 1 || status.Throttled {
+		t.Errorf("应恢复配置的配额: %+v", status)
+	}
+	if got := sm.GetRateLimiter().Stats("douyu").Capacity; got != 100 {
+		t.Errorf("限流器应恢复配置的容量, got %d", got)
+	}
+}
+
+// TestThrottleUsesFallback 主服务被限流时使用备用服务
+func TestThrottleUsesFallback(t *testing.T) {
+	primary := newUpstream(t, upstreamConfig{Failures: 10, Failure: upstreamResponse{Status: http.StatusPreconditionFailed, Body: `{}`}})
+	backup := newUpstream(t, upstreamConfig{})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"bilibili": {BaseURL: primary.URL, Timeout: 5 * time.Second, Fallbacks: []string{"douyu"}},
+		"douyu":    {BaseURL: backup.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{DefaultLimit: 100})
+
+	for i := 0; i < 2; i++ {
+		if err := getX(sm, "bilibili"); err != nil {
+			t.Fatalf("应使用备用服务: %v", err)
+		}
+	}
+}
+
+// TestThrottleDisabled 关闭自适应限流时限流响应按普通错误返回
+func TestThrottleDisabled(t *testing.T) {
+	upstream := newUpstream(t, upstreamConfig{Failures: 1, Failure: upstreamResponse{Status: http.StatusTooManyRequests, Body: `{}`}})
+	sm := newTestManager(map[string]service.APISourceConfig{
+		"douyu": {BaseURL: upstream.URL, Timeout: 5 * time.Second},
+	}, service.RateLimitConfig{DefaultLimit: 100, Adaptive: service.AdaptiveConfig{Disabled: true}})
+
+	err := getX(sm, "douyu")
+	var apiErr *service.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("应返回 APIError, got %v", err)
+	}
+	if err := getX(sm, "douyu"); err != nil || upstream.Hits() != 2 {
+		t.Errorf("不应进入退避期: %v", err)
+	}
+}
+
+// TestTokenBucketScale Scale 按比例缩小速率和容量，比例为 1 时恢复配置值
+func TestTokenBucketScale(t *testing.T) {
+	limiter := service.NewRateLimiter(service.RateLimitConfig{DefaultLimit: 10, Window: time.Minute})
+
+	limiter.Scale("zhihu", 0.25)
+	if got := limiter.Stats("zhihu").Capacity; got != 2 {
+		t.Errorf("容量应缩小为 2, got %d", got)
+	}
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if limiter.Allow("zhihu") {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("应只放行 2 个请求, got %d", allowed)
+	}
+
+	limiter.Scale("zhihu", 1)
+	if got := limiter.Stats("zhihu").Capacity; got != 10 {
+		t.Errorf("应恢复配置的容量, got %d", got)
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+func newTestTieredCache(t *testing.T, memory *service.MemoryCache, disk *service.DiskCache) *service.TieredCache {
+	t.Helper()
+
+	return service.NewTieredCacheWithTiers(service.CacheConfig{TTL: time.Minute},
+		[]string{"memory", "disk"},
+		[]service.CacheManager{memory, disk})
+}
+
+// TestTieredCachePromotion 慢层级命中后提升到内存层级
+func TestTieredCachePromotion(t *testing.T) {
+	ctx := context.Background()
+	disk := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+
+	// 只写入磁盘层级，模拟重启后内存为空
+	diskOnly := service.NewTieredCacheWithTiers(service.CacheConfig{}, []string{"disk"}, []service.CacheManager{disk})
+	want := map[string]interface{}{"title": "Gitee 推荐"}
+	if err := diskOnly.Set(ctx, "widget:gitee-repos", want, time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	cache := newTestTieredCache(t, service.NewMemoryCache(service.CacheConfig{}), disk)
+
+	for i := 0; i < 2; i++ {
+		var got map[string]interface{}
+		if err := cache.Get(ctx, "widget:gitee-repos", &got); err != nil {
+			t.Fatalf("读取缓存失败: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("缓存内容不一致: got %v, want %v", got, want)
+		}
+	}
+
+	wantStats := []service.CacheTierStats{
+		{Name: "memory", Hits: 1, Misses: 1},
+		{Name: "disk", Hits: 1, Misses: 0},
+	}
+	if stats := cache.TierStats(); !reflect.DeepEqual(stats, wantStats) {
+		t.Errorf("层级统计错误: got %+v, want %+v", stats, wantStats)
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 0 {
+		t.Errorf("整体统计错误: hits=%d misses=%d", hits, misses)
+	}
+}
+
+// TestTieredCacheWriteThrough Set 写入所有层级，Delete 从所有层级删除
+func TestTieredCacheWriteThrough(t *testing.T) {
+	ctx := context.Background()
+	memory := service.NewMemoryCache(service.CacheConfig{})
+	disk := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+	cache := newTestTieredCache(t, memory, disk)
+
+	if err := cache.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	for name, tier := range map[string]service.CacheManager{"memory": memory, "disk": disk} {
+		view := service.NewTieredCacheWithTiers(service.CacheConfig{}, []string{name}, []service.CacheManager{tier})
+
+		var got string
+		if err := view.Get(ctx, "key", &got); err != nil || got != "value" {
+			t.Errorf("%s 层级未写入: %v (%q)", name, err, got)
+		}
+	}
+
+	if err := cache.Delete(ctx, "key"); err != nil {
+		t.Fatalf("删除缓存失败: %v", err)
+	}
+
+	var got string
+	if err := cache.Get(ctx, "key", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("删除后应返回 ErrCacheMiss, got %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 1 {
+		t.Errorf("整体统计错误: hits=%d misses=%d", hits, misses)
+	}
+}
+
+// TestTieredCachePromotionKeepsTTL 提升到快层级的条目保留剩余过期时间
+func TestTieredCachePromotionKeepsTTL(t *testing.T) {
+	ctx := context.Background()
+	disk := newTestDiskCache(t, service.CacheConfig{Dir: t.TempDir()})
+
+	diskOnly := service.NewTieredCacheWithTiers(service.CacheConfig{}, []string{"disk"}, []service.CacheManager{disk})
+	if err := diskOnly.Set(ctx, "short", "value", 150*time.Millisecond); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	cache := newTestTieredCache(t, service.NewMemoryCache(service.CacheConfig{}), disk)
+
+	var got string
+	if err := cache.Get(ctx, "short", &got); err != nil {
+		t.Fatalf("读取缓存失败: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if err := cache.Get(ctx, "short", &got); !errors.Is(err, service.ErrCacheMiss) {
+		t.Errorf("提升后的条目应按原过期时间失效, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/linzi007/glance-china/internal/service"
+)
+
+// upstreamResponse 上游替身返回的响应，Status 默认 200
+type upstreamResponse struct {
+	Status int
+	Header http.Header
+	Body   string
+}
+
+// upstreamConfig 上游替身的配置：前 Failures 次请求返回 Failure，之后返回 Status 和 Body
+type upstreamConfig struct {
+	Status   int // 默认 200，可在测试中途通过 SetStatus 切换
+	Body     string
+	Failures int32
+	Failure  upstreamResponse
+}
+
+// testUpstream 按配置返回响应并统计请求次数的上游替身
+type testUpstream struct {
+	*httptest.Server
+	hits   int32
+	status int32
+}
+
+// newUpstream 启动上游替身，测试结束时自动关闭
+func newUpstream(t *testing.T, config upstreamConfig) *testUpstream {
+	t.Helper()
+
+	u := &testUpstream{status: int32(statusOr(config.Status))}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&u.hits, 1) <= config.Failures {
+			for key, values := range config.Failure.Header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statusOr(config.Failure.Status))
+			w.Write([]byte(config.Failure.Body))
+			return
+		}
+		w.WriteHeader(int(atomic.LoadInt32(&u.status)))
+		w.Write([]byte(config.Body))
+	}))
+	t.Cleanup(u.Close)
+
+	return u
+}
+
+// SetStatus 切换之后请求返回的状态码
+func (u *testUpstream) SetStatus(status int) {
+	atomic.StoreInt32(&u.status, int32(status))
+}
+
+// Hits 返回上游收到的请求次数
+func (u *testUpstream) Hits() int32 {
+	return atomic.LoadInt32(&u.hits)
+}
+
+func statusOr(status int) int {
+	if status == 0 {
+		return http.StatusOK
+	}
+	return status
+}
+
+// newTestManager 创建请求 sources 中各上游的服务管理器，未设置 DefaultLimit 时限流配额足够大，不影响测试
+func newTestManager(sources map[string]service.APISourceConfig, rateLimit service.RateLimitConfig) *service.ServiceManager {
+	if rateLimit.DefaultLimit == 0 {
+		rateLimit.DefaultLimit = 1000
+	}
+	return service.NewServiceManager(&service.Config{
+		APISources: sources,
+		RateLimit:  rateLimit,
+	})
+}
+
+func getX(sm *service.ServiceManager, name string) error {
+	_, err := sm.RequestWithFallback(context.Background(), name, &service.APIRequest{Method: "GET", Path: "/x"})
+	return err
+}
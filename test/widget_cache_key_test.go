@@ -0,0 +1,122 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// cacheKeyConfigs 每种组件若干互不相同的配置，列表长度相同但内容不同的配置也必须得到不同的 key
+var cacheKeyConfigs = map[string][]string{
+	"bilibili-videos": {
+		"up-masters: [{uid: '1', name: a}]",
+		"up-masters: [{uid: '2', name: a}]",
+		"up-masters: [{uid: '1', name: b}]",
+		"up-masters: [{uid: '1', name: a}, {uid: '2', name: b}]",
+		"up-masters: [{uid: '2', name: b}, {uid: '1', name: a}]",
+		"up-masters: [{uid: '1', name: a}]\nlimit: 5",
+		"up-masters: [{uid: '1', name: a}]\nstyle: vertical-list",
+		"up-masters: [{uid: '1', name: a}]\ntitle: 关注",
+	},
+	"zhihu-trending": {
+		"categories: [tech]",
+		"categories: [science]",
+		"categories: [tech]\nlimit: 5",
+		"categories: [tech]\nshow-images: false",
+	},
+	"gitee-repos": {
+		"repositories: [a/b]",
+		"repositories: [a/c]",
+		"repositories: [a/b]\ntoken: secret",
+		"repositories: [a/b]\nshow-issues: false",
+	},
+	"weibo-hot-search": {
+		"categories: [all]",
+		"categories: [social]",
+		"categories: [all]\nlimit: 5",
+	},
+	"douyu-live": {
+		"rooms: [{room-id: '1'}]",
+		"rooms: [{room-id: '2'}]",
+		"rooms: [{room-id: '1'}]\nshow-offline: true",
+		"rooms: [{room-id: '1'}]\nlimit: 3",
+	},
+	"test-counter": {
+		"name: a",
+		"name: b",
+	},
+}
+
+func newConfiguredWidget(t *testing.T, widgetType, config string) widget.Widget {
+	t.Helper()
+
+	w, err := widget.CreateWidget(widgetType)
+	if err != nil {
+		t.Fatalf("创建组件失败: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(config), w); err != nil {
+		t.Fatalf("解析组件配置失败: %v", err)
+	}
+	return w
+}
+
+// TestWidgetCacheKeysCoverRegisteredWidgets 每个已注册组件都有对应的测试配置
+func TestWidgetCacheKeysCoverRegisteredWidgets(t *testing.T) {
+	for _, widgetType := range widget.GetRegisteredWidgets() {
+		if len(cacheKeyConfigs[widgetType]) < 2 {
+			t.Errorf("缺少组件 %s 的缓存 key 测试配置", widgetType)
+		}
+	}
+}
+
+// TestWidgetCacheKeysNeverCollide 不同组件类型、配置或语言得到的 key 互不相同
+func TestWidgetCacheKeysNeverCollide(t *testing.T) {
+	seen := make(map[string]string)
+	for widgetType, configs := range cacheKeyConfigs {
+		for _, config := range configs {
+			for _, locale := range []string{"zh-CN", "en-US"} {
+				w := newConfiguredWidget(t, widgetType, config)
+				key := w.GetCacheKey(widget.ConfigMap{"locale": locale})
+
+				if !strings.HasPrefix(key, widgetType+":") {
+					t.Errorf("key 应以组件类型开头: %s", key)
+				}
+
+				id := widgetType + " " + locale + " " + config
+				if other, exists := seen[key]; exists {
+					t.Errorf("缓存 key 冲突: %q 与 %q", id, other)
+				}
+				seen[key] = id
+			}
+		}
+	}
+}
+
+// TestWidgetCacheKeyDeterministic 相同配置得到相同 key，不受加载数据时改写的语言影响
+func TestWidgetCacheKeyDeterministic(t *testing.T) {
+	for widgetType, configs := range cacheKeyConfigs {
+		a := newConfiguredWidget(t, widgetType, configs[0])
+		b := newConfiguredWidget(t, widgetType, configs[0])
+
+		config := widget.ConfigMap{"locale": "en-US"}
+		key := a.GetCacheKey(config)
+		if key != b.GetCacheKey(config) {
+			t.Errorf("%s: 相同配置应得到相同 key", widgetType)
+		}
+
+		if initializer, ok := a.(interface{ InitLocalizer(string) }); ok {
+			initializer.InitLocalizer("en-US")
+			if a.GetCacheKey(config) != key {
+				t.Errorf("%s: 初始化语言后 key 不应改变", widgetType)
+			}
+		}
+	}
+
+	w := newConfiguredWidget(t, "zhihu-trending", "categories: [tech]")
+	if w.GetCacheKey(nil) != w.GetCacheKey(widget.ConfigMap{"locale": "zh-CN"}) {
+		t.Error("未指定语言时应使用默认语言 zh-CN")
+	}
+}
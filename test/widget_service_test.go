@@ -0,0 +1,235 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linzi007/glance-china/internal/service"
+	"github.com/linzi007/glance-china/internal/widget"
+)
+
+// fakeUpstream 模拟 Bilibili、知乎和 Gitee 接口，记录收到的请求
+type fakeUpstream struct {
+	*httptest.Server
+	requests []*http.Request
+	mu       sync.Mutex
+}
+
+var fakeUpstreamResponses = map[string]string{
+	"/bilibili/x/web-interface/nav": `{"code":-101,"data":{"wbi_img":{
+		"img_url":"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png",
+		"sub_url":"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"}}}`,
+	"/bilibili/x/frontend/finger/spi": `{"code":0,"data":{"b_3":"buvid3-value","b_4":"buvid4-value"}}`,
+	"/bilibili/x/space/wbi/arc/search": `{"code":0,"data":{"list":{"vlist":[
+		{"aid":1,"title":"新视频","created":1700000000,"length":"01:30","video_review":12345,"pic":"https://i0.hdslb.com/1.jpg"}]}}}`,
+	"/zhihu/api/v3/feed/topstory/hot-lists/total": `{"data":[
+		{"target":{"id":42,"title":"热门问题","url":"https://www.zhihu.com/question/42"},"heat_value":99000}]}`,
+	"/gitee/api/v5/repos/owner/repo": `{"name":"repo","full_name":"owner/repo","html_url":"https://gitee.com/owner/repo",
+		"stargazers_count":7,"updated_at":"2024-01-01T00:00:00Z"}`,
+	"/gitee/api/v5/repos/owner/repo/releases/latest": `{"tag_name":"v1.0.0","html_url":"https://gitee.com/owner/repo/releases/v1.0.0"}`,
+}
+
+func newFakeUpstream(t testing.TB) *fakeUpstream {
+	t.Helper()
+
+	f := &fakeUpstream{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r)
+		f.mu.Unlock()
+
+		body, exists := fakeUpstreamResponses[r.URL.Path]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// requestsTo 返回发往指定路径的请求
+func (f *fakeUpstream) requestsTo(path string) []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matched []*http.Request
+	for _, r := range f.requests {
+		if r.URL.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// serviceContext 返回携带服务管理器的上下文，各 API 源指向模拟接口
+func (f *fakeUpstream) serviceContext() (context.Context, *service.ServiceManager) {
+	sm := service.NewServiceManager(&service.Config{
+		APISources: map[string]service.APISourceConfig{
+			"bilibili": {BaseURL: f.URL + "/bilibili", Timeout: 5 * time.Second},
+			"zhihu":    {BaseURL: f.URL + "/zhihu/api", Timeout: 5 * time.Second},
+			"gitee":    {BaseURL: f.URL + "/gitee/api/v5", Timeout: 5 * time.Second},
+		},
+		RateLimit: service.RateLimitConfig{DefaultLimit: 1000000},
+	})
+
+	ctx := context.WithValue(context.Background(), "serviceManager", sm)
+	return context.WithValue(ctx, "locale", "zh-CN"), sm
+}
+
+// TestBilibiliWidgetUsesAPISource Bilibili 组件请求配置的 base-url 并经过服务管理器
+func TestBilibiliWidgetUsesAPISource(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	ctx, sm := upstream.serviceContext()
+
+	w := widget.NewBilibiliVideosWidget()
+	w.UPMasters = []widget.BilibiliUPMaster{{UID: "546195", Name: "老番茄"}}
+
+	data, err := w.GetData(ctx, nil)
+	if err != nil {
+		t.Fatalf("获取数据失败: %v", err)
+	}
+
+	videos := data.(map[string]interface{})["videos"].([]widget.BilibiliVideoData)
+	if len(videos) != 1 || videos[0].Title != "新视频" || videos[0].Author != "老番茄" {
+		t.Errorf("视频数据错误: %+v", videos)
+	}
+
+	requests := upstream.requestsTo("/bilibili/x/space/wbi/arc/search")
+	if len(requests) == 0 || requests[0].URL.Query().Get("mid") != "546195" {
+		t.Fatalf("未按配置的 base-url 请求上游: %v", requests)
+	}
+	if requests[0].Header.Get("Referer") != "https://www.bilibili.com" {
+		t.Errorf("缺少 Referer 请求头")
+	}
+	if sm.GetMetrics().APIRequests["bilibili"] == 0 {
+		t.Error("请求未经过服务管理器")
+	}
+}
+
+// TestZhihuWidgetUsesAPISource 知乎组件请求配置的 base-url 并经过服务管理器
+func TestZhihuWidgetUsesAPISource(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	ctx, sm := upstream.serviceContext()
+
+	data, err := widget.NewZhihuTrendingWidget().GetData(ctx, nil)
+	if err != nil {
+		t.Fatalf("获取数据失败: %v", err)
+	}
+
+	trending := data.(map[string]interface{})["trending"].([]widget.ZhihuTrendingData)
+	if len(trending) != 1 || trending[0].Title != "热门问题" || trending[0].HeatValue != 99000 {
+		t.Errorf("热榜数据错误: %+v", trending)
+	}
+	if sm.GetMetrics().APIRequests["zhihu"] == 0 {
+		t.Error("请求未经过服务管理器")
+	}
+}
+
+// TestWidgetUsesConfiguredAPISource 组件通过 api-source 使用 type 指定类型的其他源，该源不存在时使用默认源
+func TestWidgetUsesConfiguredAPISource(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	sm := service.NewServiceManager(&service.Config{
+		APISources: map[string]service.APISourceConfig{
+			"zhihu":        {BaseURL: upstream.URL + "/unavailable", Timeout: 5 * time.Second},
+			"zhihu-mirror": {Type: "zhihu", BaseURL: upstream.URL + "/zhihu/api", Timeout: 5 * time.Second},
+			"gitee":        {BaseURL: upstream.URL + "/gitee/api/v5", Timeout: 5 * time.Second},
+		},
+		RateLimit: service.RateLimitConfig{DefaultLimit: 1000000},
+	})
+	ctx := context.WithValue(context.Background(), "serviceManager", sm)
+
+	w := widget.NewZhihuTrendingWidget()
+	w.APISource = "zhihu-mirror"
+	if _, err := w.GetData(ctx, nil); err != nil {
+		t.Fatalf("应使用 api-source 指定的源: %v", err)
+	}
+	if metrics := sm.GetMetrics(); metrics.APIRequests["zhihu-mirror"] == 0 || metrics.APIRequests["zhihu"] != 0 {
+		t.Errorf("请求应发往 zhihu-mirror: %v", metrics.APIRequests)
+	}
+
+	w.APISource = "missing"
+	if _, err := w.GetData(ctx, nil); err == nil || sm.GetMetrics().APIRequests["zhihu"] == 0 {
+		t.Errorf("api-source 不存在时应使用默认源 zhihu, got %v", err)
+	}
+
+	w.APISource = "gitee"
+	if _, err := w.GetData(ctx, nil); err == nil {
+		t.Error("api-source 的客户端类型与组件不符时应返回错误")
+	}
+}
+
+// TestWidgetConcurrentLocales 同一组件实例并发加载不同语言时各自使用上下文中的语言
+func TestWidgetConcurrentLocales(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	ctx, _ := upstream.serviceContext()
+	w := widget.NewZhihuTrendingWidget()
+	titles := map[string]string{"zh-CN": "知乎热榜", "en-US": "Zhihu Trending"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		locale := "zh-CN"
+		if i%2 == 1 {
+			locale = "en-US"
+		}
+		wg.Add(1)
+		go func(locale string) {
+			defer wg.Done()
+			data, err := w.GetData(context.WithValue(ctx, "locale", locale), nil)
+			if err != nil {
+				t.Errorf("获取数据失败: %v", err)
+				return
+			}
+			result := data.(map[string]interface{})
+			if got := result["locale"]; got != locale {
+				t.Errorf("应使用上下文中的语言 %s, got %v", locale, got)
+			}
+			if got := result["title"]; got != titles[locale] {
+				t.Errorf("%s 的标题应为 %s, got %v", locale, titles[locale], got)
+			}
+		}(locale)
+	}
+	wg.Wait()
+}
+
+// TestGiteeWidgetUsesAPISource Gitee 组件请求配置的 base-url，并携带组件配置的 token
+func TestGiteeWidgetUsesAPISource(t *testing.T) {
+	upstream := newFakeUpstream(t)
+	ctx, sm := upstream.serviceContext()
+
+	w := widget.NewGiteeReposWidget()
+	w.Repositories = []string{"owner/repo"}
+	w.Token = "secret"
+
+	data, err := w.GetData(ctx, nil)
+	if err != nil {
+		t.Fatalf("获取数据失败: %v", err)
+	}
+
+	repos := data.(map[string]interface{})["repositories"].([]widget.GiteeRepoData)
+	if len(repos) != 1 || repos[0].Stars != 7 || repos[0].LatestRelease != "v1.0.0" {
+		t.Fatalf("仓库数据错误: %+v", repos)
+	}
+
+	requests := upstream.requestsTo("/gitee/api/v5/repos/owner/repo")
+	if len(requests) == 0 || requests[0].Header.Get("Authorization") != "token secret" {
+		t.Errorf("未携带 token 请求配置的 base-url: %v", requests)
+	}
+	if sm.GetMetrics().APIRequests["gitee"] == 0 {
+		t.Error("请求未经过服务管理器")
+	}
+}
+
+// TestWidgetWithoutServiceManager 上下文中没有服务管理器时返回错误而不是 panic
+func TestWidgetWithoutServiceManager(t *testing.T) {
+	if _, err := widget.NewZhihuTrendingWidget().GetData(context.Background(), nil); err == nil {
+		t.Error("缺少服务管理器时应返回错误")
+	}
+}